@@ -0,0 +1,24 @@
+module github.com/RunzhiZhao/go-mstoolkit/cmd/mstoolkit
+
+go 1.20
+
+require (
+	github.com/RunzhiZhao/go-mstoolkit/jwt v0.1.0
+	github.com/golang-jwt/jwt/v5 v5.0.0
+)
+
+require (
+	aidanwoods.dev/go-paseto v1.5.4 // indirect
+	aidanwoods.dev/go-result v0.3.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/go-jose/go-jose/v3 v3.0.5 // indirect
+	github.com/redis/go-redis/v9 v9.11.0 // indirect
+	golang.org/x/crypto v0.33.0 // indirect
+	golang.org/x/net v0.26.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237 // indirect
+	google.golang.org/grpc v1.64.1 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+)
@@ -0,0 +1,502 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/RunzhiZhao/go-mstoolkit/jwt"
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+const jwtUsage = `usage: mstoolkit jwt <command> [flags] [token]
+
+commands:
+  keygen   generate a signing key
+  sign     sign claims into a token
+  verify   verify a token and print its claims
+  decode   print the header and claims of a token WITHOUT verifying it
+
+flags go before the token, the token is read from stdin when omitted.
+run "mstoolkit jwt <command> -h" for the flags of a command.
+`
+
+func runJWT(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, jwtUsage)
+		return exitUsage
+	}
+	c := &jwtCommand{stdin: stdin, stdout: stdout, stderr: stderr}
+	switch args[0] {
+	case "keygen":
+		return c.keygen(args[1:])
+	case "sign":
+		return c.sign(args[1:])
+	case "verify":
+		return c.verify(args[1:])
+	case "decode":
+		return c.decode(args[1:])
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, jwtUsage)
+		return exitOK
+	}
+	fmt.Fprintf(stderr, "mstoolkit jwt: unknown command %q\n\n%s", args[0], jwtUsage)
+	return exitUsage
+}
+
+type jwtCommand struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	json   bool
+}
+
+func (c *jwtCommand) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("mstoolkit jwt "+name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.BoolVar(&c.json, "json", false, "print JSON instead of human-readable output")
+	return fs
+}
+
+// parse 解析参数，返回非负数时命令应以该退出码结束
+func parse(fs *flag.FlagSet, args []string) int {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	return -1
+}
+
+func (c *jwtCommand) keygen(args []string) int {
+	fs := c.flagSet("keygen")
+	alg := fs.String("alg", string(jwt.SigningMethodEdDSA), "signing algorithm: EdDSA, RS256/RS384/RS512, ES256/ES384/ES512 or HS256/HS384/HS512")
+	out := fs.String("out", "", "write the private key to this file (mode 0600) and the public key to <out>.pub instead of stdout")
+	if code := parse(fs, args); code >= 0 {
+		return code
+	}
+
+	priv, pub, err := jwt.GenerateKey(jwt.SigningMethod(*alg))
+	if err != nil {
+		return c.fail(exitUsage, fmt.Errorf("%w: %s", err, *alg))
+	}
+	// HMAC 的公私钥是同一个密钥
+	hmac := bytes.Equal(priv, pub)
+	if hmac {
+		priv = append(priv, '\n')
+		pub = priv
+	}
+
+	if *out == "" {
+		if c.json {
+			return c.printJSON(map[string]any{"alg": *alg, "private_key": string(priv), "public_key": string(pub)})
+		}
+		fmt.Fprint(c.stdout, string(priv))
+		if !hmac {
+			fmt.Fprint(c.stdout, string(pub))
+		}
+		return exitOK
+	}
+
+	pubFile := *out + ".pub"
+	if hmac {
+		pubFile = *out
+	}
+	if err := os.WriteFile(*out, priv, 0o600); err != nil {
+		return c.fail(exitFailure, err)
+	}
+	if !hmac {
+		if err := os.WriteFile(pubFile, pub, 0o644); err != nil {
+			return c.fail(exitFailure, err)
+		}
+	}
+	if c.json {
+		return c.printJSON(map[string]any{"alg": *alg, "private_key_file": *out, "public_key_file": pubFile})
+	}
+	fmt.Fprintf(c.stdout, "private key: %s\npublic key:  %s\n", *out, pubFile)
+	return exitOK
+}
+
+func (c *jwtCommand) sign(args []string) int {
+	fs := c.flagSet("sign")
+	keyFile := fs.String("key", "", "private key or HMAC secret file (required)")
+	alg := fs.String("alg", "", "signing algorithm, derived from the key when omitted (RS256 for RSA keys, required for HMAC)")
+	claimsFile := fs.String("claims", "", `JSON claims file, "-" reads stdin`)
+	expires := fs.Duration("expires", time.Hour, "lifetime of the token when the claims have no exp")
+	kid := fs.String("kid", "", "kid header")
+	iss := fs.String("iss", "", "iss claim when not set in the claims")
+	aud := fs.String("aud", "", "comma separated aud claim when not set in the claims")
+	sub := fs.String("sub", "", "sub claim, overrides the claims file")
+	jti := fs.Bool("jti", false, "set a random jti claim when not set in the claims")
+	weak := fs.Bool("weak", false, "allow keys below the minimum strength (legacy keys only)")
+	if code := parse(fs, args); code >= 0 {
+		return code
+	}
+	if *keyFile == "" {
+		return c.fail(exitUsage, errors.New("--key is required"))
+	}
+	if *expires <= 0 {
+		return c.fail(exitUsage, errors.New("--expires must be positive"))
+	}
+
+	key, err := jwt.ReadKeyFile(*keyFile)
+	if err != nil {
+		return c.fail(exitFailure, err)
+	}
+	method, err := signingMethod(*alg, key)
+	if err != nil {
+		return c.fail(exitUsage, err)
+	}
+	if key, err = hmacSecret(method, key); err != nil {
+		return c.fail(exitFailure, err)
+	}
+
+	var claims tokenClaims
+	if *claimsFile != "" {
+		data, err := c.readInput(*claimsFile)
+		if err != nil {
+			return c.fail(exitFailure, err)
+		}
+		if err := json.Unmarshal(data, &claims); err != nil {
+			return c.fail(exitFailure, fmt.Errorf("claims %s: %w", *claimsFile, err))
+		}
+	}
+	if *sub != "" {
+		claims.Subject = *sub
+	}
+
+	opts := []jwt.Option{jwt.WithExpires(*expires)}
+	if *kid != "" {
+		opts = append(opts, jwt.WithKeyID(*kid))
+	}
+	if *iss != "" {
+		opts = append(opts, jwt.WithIssuer(*iss))
+	}
+	if *aud != "" {
+		opts = append(opts, jwt.WithAudience(strings.Split(*aud, ",")...))
+	}
+	if *jti {
+		opts = append(opts, jwt.WithJTI())
+	}
+	if *weak {
+		opts = append(opts, jwt.WithWeakSigningKey())
+	}
+	g, err := jwt.NewClaimsGenerator[tokenClaims](method, key, opts...)
+	if err != nil {
+		return c.fail(exitFailure, err)
+	}
+	tokenStr, err := g.Generate(claims)
+	if err != nil {
+		return c.fail(exitFailure, err)
+	}
+
+	if c.json {
+		return c.printJSON(map[string]any{"token": tokenStr})
+	}
+	fmt.Fprintln(c.stdout, tokenStr)
+	return exitOK
+}
+
+func (c *jwtCommand) verify(args []string) int {
+	fs := c.flagSet("verify")
+	keyFile := fs.String("key", "", "public key, certificate or HMAC secret file (required)")
+	alg := fs.String("alg", "", "expected algorithm, derived from the key when omitted (RS256 for RSA keys, required for HMAC)")
+	iss := fs.String("iss", "", "expected iss claim")
+	aud := fs.String("aud", "", "expected aud claim")
+	leeway := fs.Duration("leeway", 0, "clock skew allowed for exp/nbf/iat")
+	weak := fs.Bool("weak", false, "allow keys below the minimum strength (legacy keys only)")
+	if code := parse(fs, args); code >= 0 {
+		return code
+	}
+	if *keyFile == "" {
+		return c.fail(exitUsage, errors.New("--key is required"))
+	}
+
+	tokenStr, err := c.readToken(fs.Args())
+	if err != nil {
+		return c.fail(exitUsage, err)
+	}
+	key, err := jwt.ReadKeyFile(*keyFile)
+	if err != nil {
+		return c.fail(exitFailure, err)
+	}
+	method, err := signingMethod(*alg, key)
+	if err != nil {
+		return c.fail(exitUsage, err)
+	}
+	if key, err = hmacSecret(method, key); err != nil {
+		return c.fail(exitFailure, err)
+	}
+
+	var opts []jwt.VerifierOption
+	if *iss != "" {
+		opts = append(opts, jwt.WithExpectedIssuer(*iss))
+	}
+	if *aud != "" {
+		opts = append(opts, jwt.WithExpectedAudience(*aud))
+	}
+	if *leeway > 0 {
+		opts = append(opts, jwt.WithLeeway(*leeway))
+	}
+	if *weak {
+		opts = append(opts, jwt.WithWeakVerificationKey())
+	}
+	v, err := jwt.NewClaimsVerifier[tokenClaims](method, key, opts...)
+	if err != nil {
+		return c.fail(exitFailure, err)
+	}
+
+	if _, err := v.Verify(tokenStr); err != nil {
+		reason := jwt.ReasonOf(err)
+		if c.json {
+			c.printJSON(map[string]any{"valid": false, "reason": reason, "error": err.Error()})
+			return exitFailure
+		}
+		fmt.Fprintf(c.stderr, "invalid token (%s): %v\n", reason, err)
+		return exitFailure
+	}
+	header, claims, err := decodeToken(tokenStr)
+	if err != nil {
+		return c.fail(exitFailure, err)
+	}
+
+	if c.json {
+		return c.printJSON(map[string]any{"valid": true, "alg": method, "header": header, "claims": claims})
+	}
+	fmt.Fprintf(c.stdout, "valid token, signed with %s\n\n", method)
+	c.printToken(header, claims)
+	return exitOK
+}
+
+func (c *jwtCommand) decode(args []string) int {
+	fs := c.flagSet("decode")
+	if code := parse(fs, args); code >= 0 {
+		return code
+	}
+	tokenStr, err := c.readToken(fs.Args())
+	if err != nil {
+		return c.fail(exitUsage, err)
+	}
+	header, claims, err := decodeToken(tokenStr)
+	if err != nil {
+		return c.fail(exitFailure, err)
+	}
+
+	if c.json {
+		out := map[string]any{"verified": false, "header": header}
+		if claims != nil {
+			out["claims"] = claims
+		}
+		return c.printJSON(out)
+	}
+	fmt.Fprintln(c.stderr, "warning: the signature is NOT verified, use \"mstoolkit jwt verify\" to verify it")
+	c.printToken(header, claims)
+	return exitOK
+}
+
+// tokenClaims 注册 claims 之外的字段保存在 extra 中，签发与验证时原样保留
+type tokenClaims struct {
+	gojwt.RegisteredClaims
+	extra map[string]json.RawMessage
+}
+
+var registeredClaimNames = []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti"}
+
+func (c tokenClaims) MarshalJSON() ([]byte, error) {
+	registered, err := json.Marshal(c.RegisteredClaims)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]json.RawMessage, len(c.extra)+len(registeredClaimNames))
+	for k, v := range c.extra {
+		m[k] = v
+	}
+	if err := json.Unmarshal(registered, &m); err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+func (c *tokenClaims) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &c.RegisteredClaims); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &c.extra); err != nil {
+		return err
+	}
+	for _, name := range registeredClaimNames {
+		delete(c.extra, name)
+	}
+	return nil
+}
+
+// hmacSecret HMAC 密钥文件按 jwt.ParseHMACSecret 解码，keygen 输出的是 "base64:" 编码的密钥
+func hmacSecret(method jwt.SigningMethod, key []byte) ([]byte, error) {
+	switch method {
+	case jwt.SigningMethodHS256, jwt.SigningMethodHS384, jwt.SigningMethodHS512:
+		return jwt.ParseHMACSecret(key)
+	}
+	return key, nil
+}
+
+// signingMethod 未指定 --alg 时由密钥类型推导算法，RSA 密钥为 RS256；不读取 token 头部的 alg，避免由 token 决定验证算法
+func signingMethod(alg string, key []byte) (jwt.SigningMethod, error) {
+	if alg != "" {
+		return jwt.SigningMethod(alg), nil
+	}
+
+	var pub crypto.PublicKey
+	if priv, err := jwt.ParsePrivateKey(key); err == nil {
+		pub = priv.Public()
+	} else if pub, err = jwt.ParsePublicKey(key); err != nil {
+		return "", errors.New("--alg is required for HMAC secrets")
+	}
+
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PublicKey:
+		switch k.Curve.Params().BitSize {
+		case 256:
+			return jwt.SigningMethodES256, nil
+		case 384:
+			return jwt.SigningMethodES384, nil
+		case 521:
+			return jwt.SigningMethodES512, nil
+		}
+	}
+	return "", fmt.Errorf("cannot derive the algorithm from a %T key, use --alg", pub)
+}
+
+// decodeToken 解码 JWS 的头部与 claims，不验证签名；JWE 只返回头部
+func decodeToken(tokenStr string) (header, claims map[string]any, err error) {
+	parts := strings.Split(tokenStr, ".")
+	if len(parts) != 3 && len(parts) != 5 {
+		return nil, nil, fmt.Errorf("token has %d segments, want 3 (JWS) or 5 (JWE)", len(parts))
+	}
+	if header, err = decodeSegment(parts[0]); err != nil {
+		return nil, nil, fmt.Errorf("header: %w", err)
+	}
+	if len(parts) == 5 {
+		return header, nil, nil
+	}
+	if claims, err = decodeSegment(parts[1]); err != nil {
+		return nil, nil, fmt.Errorf("claims: %w", err)
+	}
+	return header, claims, nil
+}
+
+func decodeSegment(seg string) (map[string]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var m map[string]any
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// printToken 输出头部、claims 以及可读的时间 claims
+func (c *jwtCommand) printToken(header, claims map[string]any) {
+	h, _ := json.MarshalIndent(header, "", "  ")
+	fmt.Fprintf(c.stdout, "header:\n%s\n", h)
+	if claims == nil {
+		fmt.Fprintln(c.stdout, "claims: encrypted (JWE), decrypt it with the recipient key first")
+		return
+	}
+	b, _ := json.MarshalIndent(claims, "", "  ")
+	fmt.Fprintf(c.stdout, "claims:\n%s\n", b)
+
+	now := time.Now()
+	var times []string
+	for _, name := range []string{"iat", "nbf", "exp"} {
+		n, ok := claims[name].(json.Number)
+		if !ok {
+			continue
+		}
+		f, err := n.Float64()
+		if err != nil {
+			continue
+		}
+		t := time.Unix(int64(f), 0)
+		d := t.Sub(now).Round(time.Second)
+		rel := "in " + d.String()
+		if d < 0 {
+			rel = (-d).String() + " ago"
+		}
+		times = append(times, fmt.Sprintf("%s: %s (%s)", name, t.UTC().Format(time.RFC3339), rel))
+	}
+	sort.Strings(times)
+	for _, s := range times {
+		fmt.Fprintln(c.stdout, s)
+	}
+}
+
+// readToken 从参数或 stdin 读取 token，去除 "Bearer " 前缀
+func (c *jwtCommand) readToken(args []string) (string, error) {
+	var tokenStr string
+	switch len(args) {
+	case 0:
+		data, err := io.ReadAll(c.stdin)
+		if err != nil {
+			return "", err
+		}
+		tokenStr = string(data)
+	case 1:
+		tokenStr = args[0]
+	default:
+		return "", errors.New("expected a single token")
+	}
+	tokenStr = strings.TrimSpace(tokenStr)
+	tokenStr = strings.TrimSpace(strings.TrimPrefix(tokenStr, "Bearer "))
+	if tokenStr == "" {
+		return "", errors.New("no token given")
+	}
+	return tokenStr, nil
+}
+
+func (c *jwtCommand) readInput(name string) ([]byte, error) {
+	if name == "-" {
+		return io.ReadAll(c.stdin)
+	}
+	return os.ReadFile(name)
+}
+
+func (c *jwtCommand) printJSON(v any) int {
+	enc := json.NewEncoder(c.stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintln(c.stderr, err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// fail 输出错误并返回退出码，JSON 模式下输出 {"error": ...}
+func (c *jwtCommand) fail(code int, err error) int {
+	if c.json {
+		c.printJSON(map[string]any{"error": err.Error()})
+		return code
+	}
+	fmt.Fprintln(c.stderr, "error:", err)
+	return code
+}
@@ -0,0 +1,46 @@
+// Command mstoolkit is the command line companion of go-mstoolkit.
+//
+//	mstoolkit jwt keygen --alg EdDSA --out jwt.pem
+//	mstoolkit jwt sign --key jwt.pem --claims claims.json
+//	mstoolkit jwt verify --key jwt.pem.pub <token>
+//	mstoolkit jwt decode <token>
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	exitOK      = 0
+	exitFailure = 1 // 验证失败或执行出错
+	exitUsage   = 2
+)
+
+const usage = `usage: mstoolkit <command> [arguments]
+
+commands:
+  jwt    generate keys, sign, verify and decode tokens
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run 执行命令并返回退出码，便于测试
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+	switch args[0] {
+	case "jwt":
+		return runJWT(args[1:], stdin, stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	}
+	fmt.Fprintf(stderr, "mstoolkit: unknown command %q\n\n%s", args[0], usage)
+	return exitUsage
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/RunzhiZhao/go-mstoolkit/jwt"
+)
+
+// runCLI 在进程内执行命令，返回退出码与输出
+func runCLI(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestJWT(t *testing.T) {
+	for _, alg := range []string{"EdDSA", "RS256", "ES256", "HS256"} {
+		t.Run(alg, func(t *testing.T) {
+			dir := t.TempDir()
+			keyFile := filepath.Join(dir, "jwt.pem")
+			pubFile := keyFile + ".pub"
+			if alg == "HS256" {
+				pubFile = keyFile
+			}
+
+			code, out, errOut := runCLI(t, "", "jwt", "keygen", "--alg", alg, "--out", keyFile, "--json")
+			if code != exitOK {
+				t.Fatalf("keygen exit %d: %s", code, errOut)
+			}
+			var keygen struct {
+				PublicKeyFile string `json:"public_key_file"`
+			}
+			if err := json.Unmarshal([]byte(out), &keygen); err != nil {
+				t.Fatal(err)
+			}
+			if keygen.PublicKeyFile != pubFile {
+				t.Errorf("public_key_file = %q, want %q", keygen.PublicKeyFile, pubFile)
+			}
+			if fi, err := os.Stat(keyFile); err != nil || fi.Mode().Perm() != 0o600 {
+				t.Errorf("private key file mode = %v, %v", fi, err)
+			}
+
+			// HMAC 需要显式指定算法，其他算法由密钥推导
+			var algArgs []string
+			if alg == "HS256" {
+				algArgs = []string{"--alg", alg}
+			}
+			claimsFile := filepath.Join(dir, "claims.json")
+			if err := os.WriteFile(claimsFile, []byte(`{"sub":"42","role":"admin","scopes":["a","b"]}`), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			args := append([]string{"jwt", "sign", "--key", keyFile, "--claims", claimsFile, "--iss", "cli", "--kid", "k1"}, algArgs...)
+			code, out, errOut = runCLI(t, "", args...)
+			if code != exitOK {
+				t.Fatalf("sign exit %d: %s", code, errOut)
+			}
+			tokenStr := strings.TrimSpace(out)
+			if alg == "HS256" {
+				// 签名使用解码后的密钥，与库的 ParseHMACSecret 一致
+				data, err := os.ReadFile(keyFile)
+				if err != nil {
+					t.Fatal(err)
+				}
+				secret, err := jwt.ParseHMACSecret(data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				v, err := jwt.NewTokenVerifier(jwt.SigningMethodHS256, secret, jwt.WithExpectedIssuer("cli"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := v.Verify(tokenStr); err != nil {
+					t.Errorf("library Verify() error = %v", err)
+				}
+			}
+
+			args = append([]string{"jwt", "verify", "--key", pubFile, "--iss", "cli", "--json"}, algArgs...)
+			code, out, errOut = runCLI(t, "Bearer "+tokenStr+"\n", args...)
+			if code != exitOK {
+				t.Fatalf("verify exit %d: %s %s", code, out, errOut)
+			}
+			var verified struct {
+				Valid  bool           `json:"valid"`
+				Alg    string         `json:"alg"`
+				Header map[string]any `json:"header"`
+				Claims map[string]any `json:"claims"`
+			}
+			if err := json.Unmarshal([]byte(out), &verified); err != nil {
+				t.Fatal(err)
+			}
+			if !verified.Valid || verified.Alg != alg || verified.Header["kid"] != "k1" {
+				t.Errorf("verify = %s", out)
+			}
+			if verified.Claims["sub"] != "42" || verified.Claims["role"] != "admin" || verified.Claims["iss"] != "cli" || verified.Claims["exp"] == nil {
+				t.Errorf("verify claims = %v", verified.Claims)
+			}
+
+			// 篡改签名
+			tampered := tokenStr[:len(tokenStr)-4] + "AAAA"
+			if tampered == tokenStr {
+				tampered = tokenStr[:len(tokenStr)-4] + "BBBB"
+			}
+			args = append([]string{"jwt", "verify", "--key", pubFile, "--json"}, algArgs...)
+			code, out, _ = runCLI(t, "", append(args, tampered)...)
+			if code != exitFailure || !strings.Contains(out, `"reason": "signature_invalid"`) {
+				t.Errorf("verify tampered exit %d: %s", code, out)
+			}
+
+			// 期望的 iss 不匹配
+			args = append([]string{"jwt", "verify", "--key", pubFile, "--iss", "other"}, algArgs...)
+			code, _, errOut = runCLI(t, "", append(args, tokenStr)...)
+			if code != exitFailure || !strings.Contains(errOut, "invalid token") {
+				t.Errorf("verify wrong iss exit %d: %s", code, errOut)
+			}
+
+			code, out, errOut = runCLI(t, "", "jwt", "decode", tokenStr)
+			if code != exitOK || !strings.Contains(out, `"role": "admin"`) || !strings.Contains(out, "exp: ") {
+				t.Errorf("decode exit %d: %s", code, out)
+			}
+			if !strings.Contains(errOut, "NOT verified") {
+				t.Errorf("decode warning = %q", errOut)
+			}
+		})
+	}
+}
+
+func TestJWTVerifyAlg(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "rs.pem")
+	if code, _, errOut := runCLI(t, "", "jwt", "keygen", "--alg", "RS384", "--out", keyFile); code != exitOK {
+		t.Fatalf("keygen exit %d: %s", code, errOut)
+	}
+	code, out, errOut := runCLI(t, "", "jwt", "sign", "--key", keyFile, "--alg", "RS384", "--sub", "1")
+	if code != exitOK {
+		t.Fatalf("sign exit %d: %s", code, errOut)
+	}
+	tokenStr := strings.TrimSpace(out)
+
+	// 未指定 --alg 时 RSA 密钥按 RS256 验证，不采用 token 头部的 alg
+	code, _, errOut = runCLI(t, "", "jwt", "verify", "--key", keyFile+".pub", tokenStr)
+	if code != exitFailure || !strings.Contains(errOut, "invalid token") {
+		t.Errorf("verify without --alg exit %d: %s", code, errOut)
+	}
+	code, out, errOut = runCLI(t, "", "jwt", "verify", "--key", keyFile+".pub", "--alg", "RS384", tokenStr)
+	if code != exitOK || !strings.Contains(out, "signed with RS384") {
+		t.Errorf("verify --alg RS384 exit %d: %s %s", code, out, errOut)
+	}
+}
+
+func TestJWTErrors(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "hs.key")
+	if code, _, errOut := runCLI(t, "", "jwt", "keygen", "--alg", "HS256", "--out", keyFile); code != exitOK {
+		t.Fatalf("keygen exit %d: %s", code, errOut)
+	}
+
+	tests := []struct {
+		name     string
+		stdin    string
+		args     []string
+		wantCode int
+		wantErr  string
+	}{
+		{name: "no command", args: []string{"jwt"}, wantCode: exitUsage, wantErr: "usage"},
+		{name: "unknown command", args: []string{"jwt", "foo"}, wantCode: exitUsage, wantErr: "unknown command"},
+		{name: "unknown alg", args: []string{"jwt", "keygen", "--alg", "none"}, wantCode: exitUsage, wantErr: "none"},
+		{name: "sign without key", args: []string{"jwt", "sign"}, wantCode: exitUsage, wantErr: "--key is required"},
+		{name: "HMAC without alg", args: []string{"jwt", "sign", "--key", keyFile}, wantCode: exitUsage, wantErr: "--alg is required"},
+		{name: "missing key file", args: []string{"jwt", "verify", "--key", filepath.Join(dir, "missing"), "a.b.c"}, wantCode: exitFailure, wantErr: "missing"},
+		{name: "no token", args: []string{"jwt", "decode"}, wantCode: exitUsage, wantErr: "no token"},
+		{name: "malformed token", args: []string{"jwt", "decode", "abc"}, wantCode: exitFailure, wantErr: "segments"},
+		{name: "bad flag", args: []string{"jwt", "decode", "--nope"}, wantCode: exitUsage, wantErr: "nope"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, errOut := runCLI(t, tt.stdin, tt.args...)
+			if code != tt.wantCode || !strings.Contains(errOut, tt.wantErr) {
+				t.Errorf("exit %d, stderr %q, want exit %d containing %q", code, errOut, tt.wantCode, tt.wantErr)
+			}
+		})
+	}
+
+	code, out, _ := runCLI(t, "", "jwt", "decode", "--json", "abc")
+	if code != exitFailure || !strings.Contains(out, `"error"`) {
+		t.Errorf("decode --json exit %d: %s", code, out)
+	}
+}
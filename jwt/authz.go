@@ -0,0 +1,179 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrInsufficientScope token 缺少所需的 scope
+	ErrInsufficientScope = fmt.Errorf("%w: insufficient scope", ErrPermissionDenied)
+	// ErrMissingRole token 不包含任一所需的角色
+	ErrMissingRole = fmt.Errorf("%w: missing role", ErrPermissionDenied)
+)
+
+// ScopedClaims claims carrying scopes and roles, implemented by TokenInfo (and TokenClaims)
+type ScopedClaims interface {
+	GetScopes() []string
+	GetRoles() []string
+}
+
+// Policy authorizes verified claims, claims is the pointer stored in the context by the middleware
+// (e.g. *TokenClaims), returns nil when allowed
+type Policy func(claims any) error
+
+// scopeError 记录所需的 scope，HTTP 适配器据此返回 WWW-Authenticate 的 scope 参数
+type scopeError struct {
+	scopes []string
+}
+
+func (e *scopeError) Error() string {
+	return ErrInsufficientScope.Error() + ": " + strings.Join(e.scopes, " ")
+}
+
+func (e *scopeError) Unwrap() error {
+	return ErrInsufficientScope
+}
+
+// RequireScopes require claims to carry all scopes
+func RequireScopes(scopes ...string) Policy {
+	return func(claims any) error {
+		sc, ok := claims.(ScopedClaims)
+		if !ok {
+			return &scopeError{scopes: scopes}
+		}
+		for _, s := range scopes {
+			if !containsString(sc.GetScopes(), s) {
+				return &scopeError{scopes: scopes}
+			}
+		}
+		return nil
+	}
+}
+
+// RequireAnyRole require claims to carry at least one of roles
+func RequireAnyRole(roles ...string) Policy {
+	return func(claims any) error {
+		if sc, ok := claims.(ScopedClaims); ok {
+			for _, r := range roles {
+				if containsString(sc.GetRoles(), r) {
+					return nil
+				}
+			}
+		}
+		return ErrMissingRole
+	}
+}
+
+// RequireClaims require pred to return true for claims of type T, e.g.
+//
+//	jwt.RequireClaims(func(c *MyClaims) bool { return c.TenantID == "acme" })
+func RequireClaims[T any](pred func(claims *T) bool) Policy {
+	return func(claims any) error {
+		c, ok := claims.(*T)
+		if !ok || !pred(c) {
+			return ErrPermissionDenied
+		}
+		return nil
+	}
+}
+
+// AllOf require all policies to allow, the first denial is returned
+func AllOf(policies ...Policy) Policy {
+	return func(claims any) error {
+		for _, p := range policies {
+			if err := p(claims); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// AnyOf require at least one of policies to allow, the last denial is returned
+func AnyOf(policies ...Policy) Policy {
+	return func(claims any) error {
+		err := ErrPermissionDenied
+		for _, p := range policies {
+			if err = p(claims); err == nil {
+				return nil
+			}
+		}
+		return err
+	}
+}
+
+// authorize 对 ctx 中的 claims 执行 p，匿名请求返回 ErrMissingToken
+func authorize(ctx context.Context, p Policy) error {
+	claims := ctx.Value(claimsContextKey{})
+	if claims == nil {
+		return ErrMissingToken
+	}
+	return p(claims)
+}
+
+// PolicyTable maps routes to policies, routes are
+//
+//	"/orders"                      exact path
+//	"/admin/"                      path prefix, like http.ServeMux
+//	"POST /orders"                 http method and path
+//	"/shop.v1.OrderService/Create" grpc full method
+//	"/shop.v1.OrderService/"       all methods of a grpc service
+//
+// 最长匹配优先，精确匹配优于前缀匹配，指定 http method 的优先；没有匹配的路由不做授权，只要求认证
+type PolicyTable struct {
+	routes []policyRoute
+}
+
+type policyRoute struct {
+	method string
+	path   string
+	prefix bool
+	policy Policy
+}
+
+// NewPolicyTable new a route to policy table
+func NewPolicyTable(routes map[string]Policy) *PolicyTable {
+	t := &PolicyTable{}
+	for pattern, p := range routes {
+		method, path, ok := strings.Cut(pattern, " ")
+		if !ok {
+			method, path = "", pattern
+		}
+		t.routes = append(t.routes, policyRoute{
+			method: method,
+			path:   strings.TrimSpace(path),
+			prefix: strings.HasSuffix(path, "/"),
+			policy: p,
+		})
+	}
+	// 按优先级排序，lookup 返回第一个匹配
+	sort.Slice(t.routes, func(i, j int) bool {
+		a, b := t.routes[i], t.routes[j]
+		if a.prefix != b.prefix {
+			return !a.prefix
+		}
+		if len(a.path) != len(b.path) {
+			return len(a.path) > len(b.path)
+		}
+		return a.method != "" && b.method == ""
+	})
+	return t
+}
+
+// Policy return the policy of the route, method is "" for grpc
+func (t *PolicyTable) Policy(method, path string) (Policy, bool) {
+	for _, r := range t.routes {
+		if r.method != "" && r.method != method {
+			continue
+		}
+		if r.path == path || (r.prefix && strings.HasPrefix(path, r.path)) {
+			return r.policy, true
+		}
+	}
+	return nil, false
+}
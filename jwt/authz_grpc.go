@@ -0,0 +1,46 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewUnaryAuthorizer new a unary server interceptor authorizing calls with the policy of the full method in table,
+// must be chained after the authentication interceptor. 拒绝时返回 codes.PermissionDenied
+func NewUnaryAuthorizer(table *PolicyTable) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := authorizeGRPC(ctx, table, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// NewStreamAuthorizer new a stream server interceptor, see NewUnaryAuthorizer
+func NewStreamAuthorizer(table *PolicyTable) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorizeGRPC(ss.Context(), table, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func authorizeGRPC(ctx context.Context, table *PolicyTable, fullMethod string) error {
+	p, ok := table.Policy("", fullMethod)
+	if !ok {
+		return nil
+	}
+	err := authorize(ctx, p)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrMissingToken):
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	return status.Error(codes.PermissionDenied, err.Error())
+}
@@ -0,0 +1,44 @@
+package jwt
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// RequirePolicy new a net/http middleware authorizing the claims stored by the authentication middleware with p,
+// denials return 403 insufficient_scope, anonymous requests return 401
+func RequirePolicy(p Policy, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	return newHTTPAuthorizer(func(*http.Request) (Policy, bool) { return p, true }, opts)
+}
+
+// NewHTTPAuthorizer new a net/http middleware authorizing requests with the policy of the matched route in table,
+// must be placed after the authentication middleware
+func NewHTTPAuthorizer(table *PolicyTable, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	return newHTTPAuthorizer(func(r *http.Request) (Policy, bool) { return table.Policy(r.Method, r.URL.Path) }, opts)
+}
+
+func newHTTPAuthorizer(lookup func(r *http.Request) (Policy, bool), opts []MiddlewareOption) func(http.Handler) http.Handler {
+	m := newHTTPMiddleware(opts)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if p, ok := lookup(r); ok {
+				if err := authorize(r.Context(), p); err != nil {
+					m.errorHandler(w, r, err)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeForbidden 按 RFC 6750 3.1 返回 403 insufficient_scope，缺少 scope 时附带 scope 参数
+func writeForbidden(w http.ResponseWriter, realm string, err error) {
+	var scope string
+	var se *scopeError
+	if errors.As(err, &se) {
+		scope = strings.Join(se.scopes, " ")
+	}
+	writeBearerError(w, realm, http.StatusForbidden, "insufficient_scope", "", scope)
+}
@@ -0,0 +1,181 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPolicies(t *testing.T) {
+	claims := &TokenClaims{TokenInfo: TokenInfo{Scopes: []string{"orders:read", "orders:write"}, Roles: []string{"billing"}}}
+	tenant := &testTenantClaims{TenantID: "acme"}
+
+	tests := []struct {
+		name    string
+		policy  Policy
+		claims  any
+		wantErr error
+	}{
+		{name: "all scopes", policy: RequireScopes("orders:read", "orders:write"), claims: claims},
+		{name: "missing scope", policy: RequireScopes("orders:read", "admin"), claims: claims, wantErr: ErrInsufficientScope},
+		{name: "any role", policy: RequireAnyRole("admin", "billing"), claims: claims},
+		{name: "missing role", policy: RequireAnyRole("admin"), claims: claims, wantErr: ErrMissingRole},
+		{name: "claims without scopes", policy: RequireScopes("orders:read"), claims: tenant, wantErr: ErrInsufficientScope},
+		{name: "predicate", policy: RequireClaims(func(c *testTenantClaims) bool { return c.TenantID == "acme" }), claims: tenant},
+		{name: "predicate denied", policy: RequireClaims(func(c *testTenantClaims) bool { return c.TenantID == "other" }), claims: tenant, wantErr: ErrPermissionDenied},
+		{name: "predicate wrong type", policy: RequireClaims(func(c *testTenantClaims) bool { return true }), claims: claims, wantErr: ErrPermissionDenied},
+		{name: "all of", policy: AllOf(RequireScopes("orders:read"), RequireAnyRole("admin")), claims: claims, wantErr: ErrMissingRole},
+		{name: "any of", policy: AnyOf(RequireAnyRole("admin"), RequireScopes("orders:read")), claims: claims},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy(tt.claims)
+			if (tt.wantErr == nil && err != nil) || !errors.Is(err, tt.wantErr) {
+				t.Errorf("policy error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && !errors.Is(err, ErrPermissionDenied) {
+				t.Errorf("policy error = %v, want wrapping %v", err, ErrPermissionDenied)
+			}
+		})
+	}
+}
+
+func TestPolicyTable(t *testing.T) {
+	named := func(name string) Policy {
+		return func(any) error { return errors.New(name) }
+	}
+	table := NewPolicyTable(map[string]Policy{
+		"/orders":                      named("orders"),
+		"POST /orders":                 named("post orders"),
+		"/admin/":                      named("admin"),
+		"/admin/users/":                named("admin users"),
+		"/shop.v1.OrderService/":       named("order service"),
+		"/shop.v1.OrderService/Delete": named("order delete"),
+	})
+
+	tests := []struct {
+		method, path string
+		want         string
+	}{
+		{method: "GET", path: "/orders", want: "orders"},
+		{method: "POST", path: "/orders", want: "post orders"},
+		{method: "GET", path: "/orders/1"},
+		{method: "GET", path: "/admin/settings", want: "admin"},
+		{method: "GET", path: "/admin/users/1", want: "admin users"},
+		{path: "/shop.v1.OrderService/Get", want: "order service"},
+		{path: "/shop.v1.OrderService/Delete", want: "order delete"},
+		{path: "/shop.v1.CartService/Get"},
+	}
+	for _, tt := range tests {
+		p, ok := table.Policy(tt.method, tt.path)
+		var got string
+		if ok {
+			got = p(nil).Error()
+		}
+		if got != tt.want {
+			t.Errorf("Policy(%q, %q) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPAuthorizer(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	g, err := NewTokenGenerator(SigningMethodEdDSA, keys[SigningMethodEdDSA].privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewTokenVerifier(SigningMethodEdDSA, keys[SigningMethodEdDSA].publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := NewPolicyTable(map[string]Policy{
+		"/orders": RequireScopes("orders:read"),
+		"/admin/": RequireAnyRole("admin"),
+	})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	h := NewTokenHTTPMiddleware(v, WithAnonymous())(NewHTTPAuthorizer(table, WithRealm("api"))(ok))
+
+	reader, err := g.Generate(TokenInfo{Scopes: []string{"orders:read"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name          string
+		path          string
+		token         string
+		wantStatus    int
+		wantChallenge string
+	}{
+		{name: "allowed", path: "/orders", token: reader, wantStatus: http.StatusOK},
+		{name: "missing role", path: "/admin/users", token: reader, wantStatus: http.StatusForbidden,
+			wantChallenge: `Bearer realm="api", error="insufficient_scope"`},
+		{name: "anonymous on protected route", path: "/orders", wantStatus: http.StatusUnauthorized,
+			wantChallenge: `Bearer realm="api"`},
+		{name: "anonymous on public route", path: "/public", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("WWW-Authenticate"); got != tt.wantChallenge {
+				t.Errorf("WWW-Authenticate = %q, want %q", got, tt.wantChallenge)
+			}
+		})
+	}
+
+	// 缺少 scope 时返回所需的 scope
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(ContextWithClaims(r.Context(), &TokenClaims{}))
+	RequirePolicy(RequireScopes("orders:read", "orders:write"))(ok).ServeHTTP(w, r)
+	if want := `Bearer error="insufficient_scope", scope="orders:read orders:write"`; w.Code != http.StatusForbidden || w.Header().Get("WWW-Authenticate") != want {
+		t.Errorf("RequirePolicy() status = %d, WWW-Authenticate = %q, want %q", w.Code, w.Header().Get("WWW-Authenticate"), want)
+	}
+}
+
+func TestGRPCAuthorizer(t *testing.T) {
+	table := NewPolicyTable(map[string]Policy{
+		"/shop.v1.OrderService/": RequireScopes("orders:read"),
+	})
+	unary := NewUnaryAuthorizer(table)
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	call := func(ctx context.Context, method string) codes.Code {
+		_, err := unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return status.Code(err)
+	}
+
+	reader := ContextWithClaims(context.Background(), &TokenClaims{TokenInfo: TokenInfo{Scopes: []string{"orders:read"}}})
+	other := ContextWithClaims(context.Background(), &TokenClaims{})
+	if got := call(reader, "/shop.v1.OrderService/Get"); got != codes.OK {
+		t.Errorf("reader code = %v, want %v", got, codes.OK)
+	}
+	if got := call(other, "/shop.v1.OrderService/Get"); got != codes.PermissionDenied {
+		t.Errorf("other code = %v, want %v", got, codes.PermissionDenied)
+	}
+	if got := call(context.Background(), "/shop.v1.OrderService/Get"); got != codes.Unauthenticated {
+		t.Errorf("anonymous code = %v, want %v", got, codes.Unauthenticated)
+	}
+	if got := call(context.Background(), "/shop.v1.CartService/Get"); got != codes.OK {
+		t.Errorf("unmatched code = %v, want %v", got, codes.OK)
+	}
+
+	stream := NewStreamAuthorizer(table)
+	err := stream(nil, &authServerStream{ctx: other}, &grpc.StreamServerInfo{FullMethod: "/shop.v1.OrderService/Watch"},
+		func(any, grpc.ServerStream) error { return nil })
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("stream code = %v, want %v", status.Code(err), codes.PermissionDenied)
+	}
+}
@@ -0,0 +1,86 @@
+package jwt
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidClaimsType = errors.New("claims type must be a struct embedding jwt.RegisteredClaims")
+)
+
+var registeredClaimsType = reflect.TypeOf(jwt.RegisteredClaims{})
+
+// ClaimsGenerator token generator for custom claims T, T must be a struct embedding jwt.RegisteredClaims:
+//
+//	type MyClaims struct {
+//		TenantID string   `json:"tenant_id"`
+//		Scopes   []string `json:"scopes"`
+//		jwt.RegisteredClaims
+//	}
+type ClaimsGenerator[T jwt.Claims] struct {
+	*generator
+	registeredIndex []int // jwt.RegisteredClaims 在 T 中的字段路径
+}
+
+// NewClaimsGenerator new token generator for custom claims T
+func NewClaimsGenerator[T jwt.Claims](signingMethod SigningMethod, privateKey []byte, opts ...Option) (*ClaimsGenerator[T], error) {
+	g := newGenerator(nil, nil, opts)
+	if g.err != nil {
+		return nil, g.err
+	}
+	signer, err := newSigner(signingMethod, privateKey, g.allowWeakKey)
+	if err != nil {
+		return nil, err
+	}
+	g.signer = signer
+	return newClaimsGenerator[T](g)
+}
+
+// NewClaimsGeneratorWithKeyRing new token generator for custom claims T signing with the active key of ring
+func NewClaimsGeneratorWithKeyRing[T jwt.Claims](ring *KeyRing, opts ...Option) (*ClaimsGenerator[T], error) {
+	g := newGenerator(nil, ring, opts)
+	if g.err != nil {
+		return nil, g.err
+	}
+	return newClaimsGenerator[T](g)
+}
+
+func newClaimsGenerator[T jwt.Claims](g *generator) (*ClaimsGenerator[T], error) {
+	index, ok := registeredClaimsIndex(reflect.TypeOf((*T)(nil)).Elem())
+	if !ok {
+		return nil, ErrInvalidClaimsType
+	}
+	return &ClaimsGenerator[T]{
+		generator:       g,
+		registeredIndex: index,
+	}, nil
+}
+
+// Generate generate token, exp/iat/nbf and the configured iss/aud/sub/jti are filled when not set in claims
+func (g *ClaimsGenerator[T]) Generate(claims T) (string, error) {
+	rc := reflect.ValueOf(&claims).Elem().FieldByIndex(g.registeredIndex).Addr().Interface().(*jwt.RegisteredClaims)
+	if err := g.fillRegisteredClaims(rc, claims); err != nil {
+		return "", err
+	}
+	return g.sign(claims)
+}
+
+// registeredClaimsIndex 查找 t 中（可多层）嵌入的 jwt.RegisteredClaims，路径上不能有指针
+func registeredClaimsIndex(t reflect.Type) ([]int, bool) {
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	f, ok := t.FieldByName("RegisteredClaims")
+	if !ok || f.Type != registeredClaimsType {
+		return nil, false
+	}
+	for i := range f.Index {
+		if t.FieldByIndex(f.Index[:i+1]).Type.Kind() != reflect.Struct {
+			return nil, false
+		}
+	}
+	return f.Index, true
+}
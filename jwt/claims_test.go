@@ -0,0 +1,277 @@
+package jwt
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type testTenantClaims struct {
+	TenantID string   `json:"tenant_id"`
+	Scopes   []string `json:"scopes"`
+	Roles    []string `json:"roles"`
+	jwt.RegisteredClaims
+}
+
+func TestClaimsGenerator_Generate(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	g, err := NewClaimsGenerator[testTenantClaims](SigningMethodEdDSA, keys[SigningMethodEdDSA].privateKey, WithExpires(time.Hour))
+	if err != nil {
+		t.Fatalf("NewClaimsGenerator() error = %v", err)
+	}
+	v, err := NewClaimsVerifier[testTenantClaims](SigningMethodEdDSA, keys[SigningMethodEdDSA].publicKey)
+	if err != nil {
+		t.Fatalf("NewClaimsVerifier() error = %v", err)
+	}
+
+	want := testTenantClaims{
+		TenantID:         "acme",
+		Scopes:           []string{"orders:read", "orders:write"},
+		Roles:            []string{"admin", "billing"},
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "user-42"},
+	}
+	tokenStr, err := g.Generate(want)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	got, err := v.Verify(tokenStr)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if got.TenantID != want.TenantID || got.Subject != want.Subject ||
+		!reflect.DeepEqual(got.Scopes, want.Scopes) || !reflect.DeepEqual(got.Roles, want.Roles) {
+		t.Errorf("Verify() got = %+v, want %+v", got, want)
+	}
+	if got.ExpiresAt == nil || got.IssuedAt == nil || got.NotBefore == nil {
+		t.Errorf("Verify() registered claims not filled: %+v", got.RegisteredClaims)
+	}
+	if d := got.ExpiresAt.Sub(got.IssuedAt.Time); d != time.Hour {
+		t.Errorf("exp - iat = %v, want %v", d, time.Hour)
+	}
+}
+
+func TestClaimsGenerator_Generate_KeepsExplicitClaims(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	g, err := NewClaimsGenerator[testTenantClaims](SigningMethodHS256, keys[SigningMethodHS256].privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewClaimsVerifier[testTenantClaims](SigningMethodHS256, keys[SigningMethodHS256].publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := jwt.NewNumericDate(time.Now().Add(time.Minute).Truncate(time.Second))
+	tokenStr, err := g.Generate(testTenantClaims{RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: exp}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := v.Verify(tokenStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.ExpiresAt.Equal(exp.Time) {
+		t.Errorf("exp = %v, want %v", got.ExpiresAt, exp)
+	}
+}
+
+func TestNewClaimsGenerator_InvalidClaimsType(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	type pointerEmbedded struct {
+		*jwt.RegisteredClaims
+	}
+
+	if _, err := NewClaimsGenerator[jwt.MapClaims](SigningMethodHS256, keys[SigningMethodHS256].privateKey); err != ErrInvalidClaimsType {
+		t.Errorf("NewClaimsGenerator[jwt.MapClaims]() error = %v, want %v", err, ErrInvalidClaimsType)
+	}
+	if _, err := NewClaimsGenerator[pointerEmbedded](SigningMethodHS256, keys[SigningMethodHS256].privateKey); err != ErrInvalidClaimsType {
+		t.Errorf("NewClaimsGenerator[pointerEmbedded]() error = %v, want %v", err, ErrInvalidClaimsType)
+	}
+}
+
+func TestTokenGenerator_CompatibleWithClaimsVerifier(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	g, err := NewTokenGenerator(SigningMethodES256, keys[SigningMethodES256].privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenStr, err := g.Generate(TokenInfo{UserID: 7, RoleID: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := NewClaimsVerifier[TokenClaims](SigningMethodES256, keys[SigningMethodES256].publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := v.Verify(tokenStr)
+	if err != nil || got.UserID != 7 || got.RoleID != 3 {
+		t.Errorf("Verify() = %+v, %v", got, err)
+	}
+}
+
+func TestClaimsGenerator_Generate_RegisteredClaimsOptions(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	g, err := NewClaimsGenerator[TokenClaims](SigningMethodHS256, keys[SigningMethodHS256].privateKey,
+		WithIssuer("https://auth.example.com"),
+		WithAudience("orders", "billing"),
+		WithSubjectFunc(func(c jwt.Claims) string {
+			return strconv.Itoa(int(c.(TokenClaims).UserID))
+		}),
+		WithJTI(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewClaimsVerifier[TokenClaims](SigningMethodHS256, keys[SigningMethodHS256].publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jtis := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		tokenStr, err := g.Generate(TokenClaims{TokenInfo: TokenInfo{UserID: 42}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := v.Verify(tokenStr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Issuer != "https://auth.example.com" || got.Subject != "42" ||
+			!reflect.DeepEqual([]string(got.Audience), []string{"orders", "billing"}) {
+			t.Errorf("Verify() registered claims = %+v", got.RegisteredClaims)
+		}
+		if got.ID == "" || jtis[got.ID] {
+			t.Errorf("jti = %q, want unique non-empty", got.ID)
+		}
+		jtis[got.ID] = true
+	}
+
+	// claims 中已设置的值不会被覆盖
+	tokenStr, err := g.Generate(TokenClaims{RegisteredClaims: jwt.RegisteredClaims{
+		Issuer: "other", Subject: "admin", Audience: jwt.ClaimStrings{"ops"}, ID: "fixed",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := v.Verify(tokenStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Issuer != "other" || got.Subject != "admin" || got.ID != "fixed" ||
+		!reflect.DeepEqual([]string(got.Audience), []string{"ops"}) {
+		t.Errorf("Verify() registered claims = %+v", got.RegisteredClaims)
+	}
+}
+
+func TestClaimsVerifier_Verify_RegisteredClaimsChecks(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	g, err := NewClaimsGenerator[TokenClaims](SigningMethodEdDSA, keys[SigningMethodEdDSA].privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	sign := func(rc jwt.RegisteredClaims) string {
+		tokenStr, err := g.Generate(TokenClaims{RegisteredClaims: rc})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tokenStr
+	}
+	at := func(d time.Duration) *jwt.NumericDate {
+		return jwt.NewNumericDate(now.Add(d))
+	}
+
+	tests := []struct {
+		name    string
+		opts    []VerifierOption
+		claims  jwt.RegisteredClaims
+		wantErr error
+	}{
+		{
+			name:   "issuer and audience match",
+			opts:   []VerifierOption{WithExpectedIssuer("iss"), WithExpectedAudience("aud")},
+			claims: jwt.RegisteredClaims{Issuer: "iss", Audience: jwt.ClaimStrings{"other", "aud"}},
+		},
+		{
+			name:    "wrong issuer",
+			opts:    []VerifierOption{WithExpectedIssuer("iss")},
+			claims:  jwt.RegisteredClaims{Issuer: "evil"},
+			wantErr: ErrInvalidIssuer,
+		},
+		{
+			name:    "missing issuer",
+			opts:    []VerifierOption{WithExpectedIssuer("iss")},
+			wantErr: ErrInvalidIssuer,
+		},
+		{
+			name:    "wrong audience",
+			opts:    []VerifierOption{WithExpectedAudience("aud")},
+			claims:  jwt.RegisteredClaims{Audience: jwt.ClaimStrings{"other"}},
+			wantErr: ErrInvalidAudience,
+		},
+		{
+			name:    "missing audience",
+			opts:    []VerifierOption{WithExpectedAudience("aud")},
+			wantErr: ErrInvalidAudience,
+		},
+		{
+			name:    "expired",
+			claims:  jwt.RegisteredClaims{ExpiresAt: at(-time.Minute)},
+			wantErr: ErrTokenExpired,
+		},
+		{
+			name:   "expired within leeway",
+			opts:   []VerifierOption{WithLeeway(2 * time.Minute)},
+			claims: jwt.RegisteredClaims{ExpiresAt: at(-time.Minute)},
+		},
+		{
+			name:    "not valid yet",
+			claims:  jwt.RegisteredClaims{NotBefore: at(time.Minute)},
+			wantErr: ErrTokenNotValidYet,
+		},
+		{
+			name:   "not valid yet within leeway",
+			opts:   []VerifierOption{WithLeeway(2 * time.Minute)},
+			claims: jwt.RegisteredClaims{NotBefore: at(time.Minute)},
+		},
+		{
+			name:    "too old",
+			opts:    []VerifierOption{WithMaxAge(time.Hour)},
+			claims:  jwt.RegisteredClaims{IssuedAt: at(-2 * time.Hour), NotBefore: at(-2 * time.Hour)},
+			wantErr: ErrTokenTooOld,
+		},
+		{
+			name:   "max age within leeway",
+			opts:   []VerifierOption{WithMaxAge(time.Hour), WithLeeway(2 * time.Hour)},
+			claims: jwt.RegisteredClaims{IssuedAt: at(-2 * time.Hour), NotBefore: at(-2 * time.Hour)},
+		},
+		{
+			name:    "issued in the future",
+			opts:    []VerifierOption{WithMaxAge(time.Hour)},
+			claims:  jwt.RegisteredClaims{IssuedAt: at(time.Hour)},
+			wantErr: ErrTokenNotValidYet,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NewClaimsVerifier[TokenClaims](SigningMethodEdDSA, keys[SigningMethodEdDSA].publicKey, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = v.Verify(sign(tt.claims))
+			if (tt.wantErr == nil && err != nil) || !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Verify() error = %v, want wrapping %v", err, ErrInvalidToken)
+			}
+		})
+	}
+}
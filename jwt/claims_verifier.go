@@ -0,0 +1,45 @@
+package jwt
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ClaimsVerifier token verifier for custom claims T, usually a struct embedding jwt.RegisteredClaims
+type ClaimsVerifier[T jwt.Claims] struct {
+	*verifier
+}
+
+// NewClaimsVerifier new a token verifier for custom claims T
+func NewClaimsVerifier[T jwt.Claims](signingMethod SigningMethod, publicKey []byte, opts ...VerifierOption) (*ClaimsVerifier[T], error) {
+	v := newVerifier(nil, nil, nil, opts)
+	if v.err != nil {
+		return nil, v.err
+	}
+	parser, err := newParser(signingMethod, publicKey, v.allowWeakKey)
+	if err != nil {
+		return nil, err
+	}
+	v.parser = parser
+	return &ClaimsVerifier[T]{verifier: v}, nil
+}
+
+// NewClaimsVerifierWithKeyRing new a token verifier for custom claims T selecting the key by the kid header
+func NewClaimsVerifierWithKeyRing[T jwt.Claims](ring *KeyRing, opts ...VerifierOption) *ClaimsVerifier[T] {
+	return &ClaimsVerifier[T]{
+		verifier: newVerifier(nil, ring, nil, opts),
+	}
+}
+
+// Verify verify token and return its claims
+func (v *ClaimsVerifier[T]) Verify(tokenStr string) (*T, error) {
+	var claims T
+	// T 为值类型时 *T 同样实现了 jwt.Claims，解析需要传入指针
+	ptr, ok := any(&claims).(jwt.Claims)
+	if !ok {
+		return nil, ErrInvalidClaimsType
+	}
+	if err := v.parse(tokenStr, ptr); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
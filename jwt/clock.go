@@ -0,0 +1,34 @@
+package jwt
+
+import "time"
+
+// Clock provides the current time for issuing and verifying tokens, see jwttest.FakeClock for tests
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock 默认使用系统时间
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// WithClock set the clock used for the iat/nbf/exp claims, default is the system clock
+func WithClock(clock Clock) Option {
+	return func(g *generator) {
+		if clock != nil {
+			g.clock = clock
+		}
+	}
+}
+
+// WithVerifierClock set the clock used to check the exp/nbf/iat claims and expire cached revocations,
+// default is the system clock
+func WithVerifierClock(clock Clock) VerifierOption {
+	return func(v *verifier) {
+		if clock != nil {
+			v.clock = clock
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package jwt
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/RunzhiZhao/go-mstoolkit/jwt/jwttest"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testEpoch 远离当前时间，确保签发与验证只依赖注入的时钟
+var testEpoch = time.Date(2001, 2, 3, 4, 5, 6, 789, time.UTC)
+
+func TestGenerator_Clock(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	clock := jwttest.NewFakeClock(testEpoch)
+	g, err := NewClaimsGenerator[TokenClaims](SigningMethodEdDSA, keys[SigningMethodEdDSA].privateKey,
+		WithExpires(10*time.Minute), WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewClaimsVerifier[TokenClaims](SigningMethodEdDSA, keys[SigningMethodEdDSA].publicKey, WithVerifierClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenStr, err := g.Generate(TokenClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := v.Verify(tokenStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := testEpoch.Truncate(time.Second)
+	if !claims.IssuedAt.Equal(want) || !claims.NotBefore.Equal(want) || !claims.ExpiresAt.Equal(want.Add(10*time.Minute)) {
+		t.Errorf("iat = %v, nbf = %v, exp = %v, want iat = nbf = %v and exp 10m later", claims.IssuedAt, claims.NotBefore, claims.ExpiresAt, want)
+	}
+}
+
+func TestVerifier_Clock(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x24}, 32)
+
+	type format struct {
+		generator func(clock Clock) (func(TokenClaims) (string, error), error)
+		verifier  func(opts ...VerifierOption) (func(string) error, error)
+	}
+	formats := map[string]format{
+		"JWT": {
+			generator: func(clock Clock) (func(TokenClaims) (string, error), error) {
+				g, err := NewClaimsGenerator[TokenClaims](SigningMethodHS256, secret, WithExpires(10*time.Minute), WithClock(clock))
+				if err != nil {
+					return nil, err
+				}
+				return g.Generate, nil
+			},
+			verifier: func(opts ...VerifierOption) (func(string) error, error) {
+				v, err := NewClaimsVerifier[TokenClaims](SigningMethodHS256, secret, opts...)
+				if err != nil {
+					return nil, err
+				}
+				return func(tokenStr string) error {
+					_, err := v.Verify(tokenStr)
+					return err
+				}, nil
+			},
+		},
+		"PASETO": {
+			generator: func(clock Clock) (func(TokenClaims) (string, error), error) {
+				g, err := NewPasetoClaimsGenerator[TokenClaims](PasetoV4Local, secret, WithExpires(10*time.Minute), WithClock(clock))
+				if err != nil {
+					return nil, err
+				}
+				return g.Generate, nil
+			},
+			verifier: func(opts ...VerifierOption) (func(string) error, error) {
+				v, err := NewPasetoClaimsVerifier[TokenClaims](PasetoV4Local, secret, opts...)
+				if err != nil {
+					return nil, err
+				}
+				return func(tokenStr string) error {
+					_, err := v.Verify(tokenStr)
+					return err
+				}, nil
+			},
+		},
+	}
+
+	now := testEpoch.Truncate(time.Second)
+	tests := []struct {
+		name       string
+		nbf        time.Duration // 相对签发时间，0 表示 nbf 为签发时间
+		at         time.Duration // 验证时间相对签发时间
+		leeway     time.Duration
+		maxAge     time.Duration
+		wantReason Reason
+	}{
+		{name: "valid", at: 5 * time.Minute},
+		{name: "just before exp", at: 10*time.Minute - time.Second},
+		{name: "at exp", at: 10 * time.Minute, wantReason: ReasonExpired},
+		{name: "after exp", at: time.Hour, wantReason: ReasonExpired},
+		{name: "after exp within leeway", at: 10*time.Minute + 29*time.Second, leeway: 30 * time.Second},
+		{name: "after exp beyond leeway", at: 10*time.Minute + 30*time.Second, leeway: 30 * time.Second, wantReason: ReasonExpired},
+		{name: "before nbf", nbf: time.Minute, at: 59 * time.Second, wantReason: ReasonNotValidYet},
+		{name: "at nbf", nbf: time.Minute, at: time.Minute},
+		{name: "before nbf within leeway", nbf: time.Minute, at: 31 * time.Second, leeway: 30 * time.Second},
+		{name: "before nbf beyond leeway", nbf: time.Minute, at: 29 * time.Second, leeway: 30 * time.Second, wantReason: ReasonNotValidYet},
+		{name: "within max age", at: 5 * time.Minute, maxAge: 5 * time.Minute},
+		{name: "beyond max age", at: 5*time.Minute + time.Second, maxAge: 5 * time.Minute, wantReason: ReasonTooOld},
+		{name: "beyond max age within leeway", at: 5*time.Minute + time.Second, maxAge: 5 * time.Minute, leeway: time.Second},
+		{name: "issued in the future", nbf: -time.Hour, at: -time.Minute, maxAge: time.Hour, wantReason: ReasonNotValidYet},
+	}
+	for name, f := range formats {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				clock := jwttest.NewFakeClock(now)
+				generate, err := f.generator(clock)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var c TokenClaims
+				if tt.nbf != 0 {
+					c.NotBefore = jwt.NewNumericDate(now.Add(tt.nbf))
+				}
+				tokenStr, err := generate(c)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				opts := []VerifierOption{WithVerifierClock(clock), WithLeeway(tt.leeway)}
+				if tt.maxAge > 0 {
+					opts = append(opts, WithMaxAge(tt.maxAge))
+				}
+				verify, err := f.verifier(opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				clock.Advance(tt.at)
+				err = verify(tokenStr)
+				if tt.wantReason == "" {
+					if err != nil {
+						t.Errorf("Verify() error = %v", err)
+					}
+					return
+				}
+				if got := ReasonOf(err); got != tt.wantReason {
+					t.Errorf("Verify() reason = %q (%v), want %q", got, err, tt.wantReason)
+				}
+			})
+		}
+	}
+}
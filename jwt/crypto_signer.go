@@ -0,0 +1,163 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// NewClaimsGeneratorWithSigner new token generator for custom claims T signing with s, e.g. a PKCS#11, cloud KMS or
+// agent backed key whose private key never enters the process. The algorithm is derived from s.Public():
+// Ed25519 -> EdDSA, RSA -> RS256, ECDSA P-256/P-384/P-521 -> ES256/ES384/ES512
+func NewClaimsGeneratorWithSigner[T jwt.Claims](s crypto.Signer, opts ...Option) (*ClaimsGenerator[T], error) {
+	g := newGenerator(nil, nil, opts)
+	if g.err != nil {
+		return nil, g.err
+	}
+	signer, err := newCryptoSigner(s, "", g.allowWeakKey)
+	if err != nil {
+		return nil, err
+	}
+	g.signer = signer
+	return newClaimsGenerator[T](g)
+}
+
+// NewTokenGeneratorWithSigner new token generator signing with s, see NewClaimsGeneratorWithSigner
+func NewTokenGeneratorWithSigner(s crypto.Signer, opts ...Option) (*TokenGenerator, error) {
+	g, err := NewClaimsGeneratorWithSigner[TokenClaims](s, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenGenerator{claims: g}, nil
+}
+
+// newCryptoSigner 使用外部 crypto.Signer 签名，method 为空时算法由公钥类型决定；
+// 指定 method 时只要求与公钥类型匹配，RSA 公钥可以使用 RS256/RS384/RS512
+func newCryptoSigner(s crypto.Signer, method SigningMethod, allowWeak bool) (*Signer, error) {
+	if s == nil {
+		return nil, ErrInvalidPrivateKey
+	}
+	derived, err := signingMethodForPublicKey(s.Public())
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case method == "":
+		method = derived
+	case derived == SigningMethodRS256:
+		if method != SigningMethodRS256 && method != SigningMethodRS384 && method != SigningMethodRS512 {
+			return nil, ErrInvalidPrivateKey
+		}
+	case method != derived:
+		return nil, ErrInvalidPrivateKey
+	}
+	if !allowWeak {
+		if err := checkKeyStrength(method, s.Public()); err != nil {
+			return nil, &KeyError{Source: "crypto.Signer", Err: err, kind: ErrInvalidPrivateKey}
+		}
+	}
+	return &Signer{
+		signingMethod: newCryptoSigningMethod(method),
+		privateKey:    s,
+	}, nil
+}
+
+// signingMethodForPublicKey 按公钥类型推导签名算法，RSA 默认为 RS256
+func signingMethodForPublicKey(pub crypto.PublicKey) (SigningMethod, error) {
+	switch pk := pub.(type) {
+	case ed25519.PublicKey:
+		return SigningMethodEdDSA, nil
+	case *rsa.PublicKey:
+		return SigningMethodRS256, nil
+	case *ecdsa.PublicKey:
+		switch pk.Curve {
+		case elliptic.P256():
+			return SigningMethodES256, nil
+		case elliptic.P384():
+			return SigningMethodES384, nil
+		case elliptic.P521():
+			return SigningMethodES512, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %T", ErrUnsupportedKeyType, pub)
+}
+
+// cryptoSigningMethod 通过 crypto.Signer 签名的 jwt.SigningMethod，
+// 签名前计算摘要，ECDSA 的 ASN.1 签名转换为 JWS 要求的 R||S
+type cryptoSigningMethod struct {
+	method  SigningMethod
+	hash    crypto.Hash // EdDSA 为 0，对原文签名
+	keySize int         // ECDSA 的 R、S 字节长度
+}
+
+func newCryptoSigningMethod(method SigningMethod) *cryptoSigningMethod {
+	m := &cryptoSigningMethod{method: method}
+	switch method {
+	case SigningMethodRS256, SigningMethodES256:
+		m.hash = crypto.SHA256
+	case SigningMethodRS384, SigningMethodES384:
+		m.hash = crypto.SHA384
+	case SigningMethodRS512, SigningMethodES512:
+		m.hash = crypto.SHA512
+	}
+	switch method {
+	case SigningMethodES256:
+		m.keySize = 32
+	case SigningMethodES384:
+		m.keySize = 48
+	case SigningMethodES512:
+		m.keySize = 66
+	}
+	return m
+}
+
+func (m *cryptoSigningMethod) Alg() string {
+	return string(m.method)
+}
+
+// Verify 使用标准实现验证
+func (m *cryptoSigningMethod) Verify(signingString string, sig []byte, key interface{}) error {
+	return m.method.jwtMethod().Verify(signingString, sig, key)
+}
+
+func (m *cryptoSigningMethod) Sign(signingString string, key interface{}) ([]byte, error) {
+	s, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, jwt.ErrInvalidKeyType
+	}
+
+	digest := []byte(signingString)
+	if m.hash != 0 {
+		h := m.hash.New()
+		h.Write(digest)
+		digest = h.Sum(nil)
+	}
+	sig, err := s.Sign(rand.Reader, digest, m.hash)
+	if err != nil {
+		return nil, err
+	}
+	if m.keySize == 0 {
+		return sig, nil
+	}
+
+	var esig struct {
+		R, S *big.Int
+	}
+	rest, err := asn1.Unmarshal(sig, &esig)
+	if err != nil || len(rest) != 0 || esig.R.BitLen() > m.keySize*8 || esig.S.BitLen() > m.keySize*8 {
+		return nil, errors.New("malformed ECDSA signature from signer")
+	}
+	out := make([]byte, 2*m.keySize)
+	esig.R.FillBytes(out[:m.keySize])
+	esig.S.FillBytes(out[m.keySize:])
+	return out, nil
+}
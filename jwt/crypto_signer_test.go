@@ -0,0 +1,264 @@
+package jwt
+
+import (
+	"bufio"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// 测试替身：签名代理运行在独立进程中（重新执行测试二进制），私钥只存在于代理进程，
+// 客户端通过 unix socket 发送摘要并取回签名，模拟 HSM、KMS 或 ssh-agent
+
+const (
+	signingAgentEnv    = "JWT_TEST_SIGNING_AGENT"
+	signingAgentKeyEnv = "JWT_TEST_SIGNING_AGENT_KEY"
+)
+
+type agentRequest struct {
+	Op     string      `json:"op"` // "public" 或 "sign"
+	Digest []byte      `json:"digest,omitempty"`
+	Hash   crypto.Hash `json:"hash,omitempty"`
+}
+
+type agentResponse struct {
+	PublicKey []byte `json:"public_key,omitempty"` // PKIX DER
+	Signature []byte `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// TestHelperSigningAgent 不是真正的测试，由 startSigningAgent 在子进程中运行
+func TestHelperSigningAgent(t *testing.T) {
+	socket := os.Getenv(signingAgentEnv)
+	if socket == "" {
+		return
+	}
+	block, _ := pem.Decode([]byte(os.Getenv(signingAgentKeyEnv)))
+	if block == nil {
+		fmt.Fprintln(os.Stderr, "agent: missing key")
+		os.Exit(2)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "agent:", err)
+		os.Exit(2)
+	}
+	priv := key.(crypto.Signer)
+	pubDer, err := x509.MarshalPKIXPublicKey(priv.Public())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "agent:", err)
+		os.Exit(2)
+	}
+
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "agent:", err)
+		os.Exit(2)
+	}
+	fmt.Println("ready")
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			os.Exit(0)
+		}
+		go func(conn net.Conn) {
+			defer conn.Close()
+			dec, enc := json.NewDecoder(conn), json.NewEncoder(conn)
+			for {
+				var req agentRequest
+				if err := dec.Decode(&req); err != nil {
+					return
+				}
+				var resp agentResponse
+				switch req.Op {
+				case "public":
+					resp.PublicKey = pubDer
+				case "sign":
+					sig, err := priv.Sign(rand.Reader, req.Digest, req.Hash)
+					if err != nil {
+						resp.Error = err.Error()
+					}
+					resp.Signature = sig
+				default:
+					resp.Error = "unknown op " + req.Op
+				}
+				if err := enc.Encode(resp); err != nil {
+					return
+				}
+			}
+		}(conn)
+	}
+}
+
+// socketSigner 通过 unix socket 调用签名代理的 crypto.Signer
+type socketSigner struct {
+	socket string
+	pub    crypto.PublicKey
+}
+
+func (s *socketSigner) call(req agentRequest) (agentResponse, error) {
+	conn, err := net.Dial("unix", s.socket)
+	if err != nil {
+		return agentResponse{}, err
+	}
+	defer conn.Close()
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return agentResponse{}, err
+	}
+	var resp agentResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return agentResponse{}, err
+	}
+	if resp.Error != "" {
+		return agentResponse{}, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+func (s *socketSigner) Public() crypto.PublicKey {
+	return s.pub
+}
+
+func (s *socketSigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	resp, err := s.call(agentRequest{Op: "sign", Digest: digest, Hash: opts.HashFunc()})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
+
+// startSigningAgent 启动持有 privateKey 的签名代理进程，返回连接它的 crypto.Signer
+func startSigningAgent(t *testing.T, privateKey []byte) crypto.Signer {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperSigningAgent$")
+	cmd.Env = append(os.Environ(), signingAgentEnv+"="+socket, signingAgentKeyEnv+"="+string(privateKey))
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+	if line, err := bufio.NewReader(stdout).ReadString('\n'); err != nil || line != "ready\n" {
+		t.Fatalf("signing agent did not start: %q %v", line, err)
+	}
+
+	s := &socketSigner{socket: socket}
+	resp, err := s.call(agentRequest{Op: "public"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.pub, err = x509.ParsePKIXPublicKey(resp.PublicKey); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestTokenGeneratorWithSigner(t *testing.T) {
+	keys := newTestKeyPairs(t)
+
+	for _, method := range []SigningMethod{SigningMethodEdDSA, SigningMethodRS256, SigningMethodES256, SigningMethodES384, SigningMethodES512} {
+		t.Run(string(method), func(t *testing.T) {
+			signer := startSigningAgent(t, keys[method].privateKey)
+
+			g, err := NewTokenGeneratorWithSigner(signer, WithKeyID("hsm-1"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tokenStr, err := g.Generate(TokenInfo{UserID: 42})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// 使用 PEM 公钥的普通 verifier 即可验证，算法由公钥推导
+			v, err := NewTokenVerifier(method, keys[method].publicKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			info, err := v.Verify(tokenStr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.UserID != 42 {
+				t.Errorf("UserID = %d, want 42", info.UserID)
+			}
+
+			jwks, err := g.JWKS()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(jwks.Keys) != 1 || jwks.Keys[0].Alg != string(method) || jwks.Keys[0].Kid != "hsm-1" {
+				t.Errorf("JWKS() = %+v", jwks)
+			}
+		})
+	}
+}
+
+func TestKeyRingWithSigner(t *testing.T) {
+	keys := newTestKeyPairs(t)
+
+	// RSA 签名器的哈希算法由 Key.SigningMethod 决定
+	for _, method := range []SigningMethod{SigningMethodES256, SigningMethodRS256, SigningMethodRS384, SigningMethodRS512} {
+		t.Run(string(method), func(t *testing.T) {
+			signer := startSigningAgent(t, keys[method].privateKey)
+			ring, err := NewKeyRing(Key{ID: "hsm", SigningMethod: method, State: KeyActive, Signer: signer, PublicKey: keys[method].publicKey})
+			if err != nil {
+				t.Fatal(err)
+			}
+			tokenStr, err := NewTokenGeneratorWithKeyRing(ring).Generate(TokenInfo{UserID: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := NewTokenVerifierWithKeyRing(ring).Verify(tokenStr); err != nil {
+				t.Fatal(err)
+			}
+
+			// 使用 PEM 公钥的普通 verifier 按相同算法验证
+			v, err := NewTokenVerifier(method, keys[method].publicKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := v.Verify(tokenStr); err != nil {
+				t.Errorf("Verify() with %s public key error = %v", method, err)
+			}
+		})
+	}
+
+	// 公钥类型与 SigningMethod 不一致
+	ecSigner := startSigningAgent(t, keys[SigningMethodES256].privateKey)
+	rsaSigner := startSigningAgent(t, keys[SigningMethodRS256].privateKey)
+	mismatched := []Key{
+		{ID: "hsm", SigningMethod: SigningMethodES384, State: KeyActive, Signer: ecSigner, PublicKey: keys[SigningMethodES384].publicKey},
+		{ID: "hsm", SigningMethod: SigningMethodRS256, State: KeyActive, Signer: ecSigner, PublicKey: keys[SigningMethodRS256].publicKey},
+		{ID: "hsm", SigningMethod: SigningMethodES256, State: KeyActive, Signer: rsaSigner, PublicKey: keys[SigningMethodES256].publicKey},
+	}
+	for _, k := range mismatched {
+		if _, err := NewKeyRing(k); !errors.Is(err, ErrInvalidPrivateKey) {
+			t.Errorf("NewKeyRing() %s key with %T signer error = %v", k.SigningMethod, k.Signer.Public(), err)
+		}
+	}
+}
+
+func TestTokenGeneratorWithSigner_Unsupported(t *testing.T) {
+	if _, err := NewTokenGeneratorWithSigner(nil); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Errorf("NewTokenGeneratorWithSigner(nil) error = %v", err)
+	}
+}
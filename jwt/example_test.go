@@ -120,8 +120,8 @@ QfOQn8U/vy3zpfZ/Ndq8xLdZTPEv4KfWivw/1ecHsYvFqWxCLyQBpTtUjQ==
 			name: "HS256",
 			fields: fields{
 				signingMethod: SigningMethodHS256,
-				privateKey:    []byte(`[Your hmac key]`),
-				publicKey:     []byte(`[Your hmac key]`),
+				privateKey:    []byte(`[Your hmac key, at least 32 bytes]`),
+				publicKey:     []byte(`[Your hmac key, at least 32 bytes]`),
 				expires:       time.Hour,
 			},
 			args: args{
@@ -0,0 +1,96 @@
+package jwt
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	defaultRefreshBefore = 30 * time.Second
+)
+
+// TokenFetcher returns a token and its expiry, a zero expiresAt means the token never expires
+type TokenFetcher func(ctx context.Context) (token string, expiresAt time.Time, err error)
+
+// PerRPCOption is a per-RPC credentials option
+type PerRPCOption func(*perRPCCredentials)
+
+// WithRefreshBefore fetch a new token when the cached one expires within d, default is 30s
+func WithRefreshBefore(d time.Duration) PerRPCOption {
+	return func(c *perRPCCredentials) {
+		c.refreshBefore = d
+	}
+}
+
+// WithInsecureTransport allow sending tokens over connections without transport security, only for tests or local sockets
+func WithInsecureTransport() PerRPCOption {
+	return func(c *perRPCCredentials) {
+		c.insecure = true
+	}
+}
+
+// perRPCCredentials 为每次调用附加 "authorization: Bearer <token>"，token 在过期前刷新
+type perRPCCredentials struct {
+	fetch         TokenFetcher
+	refreshBefore time.Duration
+	insecure      bool
+	clock         Clock // 判断 token 是否临近过期，与 expiresAt 的来源一致
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewPerRPCCredentials new a credentials.PerRPCCredentials attaching tokens from fetch,
+// the token is cached until refresh-before its expiry
+func NewPerRPCCredentials(fetch TokenFetcher, opts ...PerRPCOption) credentials.PerRPCCredentials {
+	return newPerRPCCredentials(fetch, systemClock{}, opts)
+}
+
+func newPerRPCCredentials(fetch TokenFetcher, clock Clock, opts []PerRPCOption) *perRPCCredentials {
+	c := &perRPCCredentials{
+		fetch:         fetch,
+		refreshBefore: defaultRefreshBefore,
+		clock:         clock,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// NewGeneratorPerRPCCredentials new a credentials.PerRPCCredentials attaching tokens generated by g for info,
+// expiry is judged by the clock of g (see WithClock)
+func NewGeneratorPerRPCCredentials(g *TokenGenerator, info TokenInfo, opts ...PerRPCOption) credentials.PerRPCCredentials {
+	clock := g.claims.clock
+	return newPerRPCCredentials(func(context.Context) (string, time.Time, error) {
+		// 在签发前计算，略早于 token 实际的 exp
+		expiresAt := clock.Now().Add(g.claims.expires)
+		tokenStr, err := g.Generate(info)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		return tokenStr, expiresAt, nil
+	}, clock, opts)
+}
+
+func (c *perRPCCredentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token == "" || (!c.expiresAt.IsZero() && !c.clock.Now().Add(c.refreshBefore).Before(c.expiresAt)) {
+		token, expiresAt, err := c.fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.token, c.expiresAt = token, expiresAt
+	}
+	return map[string]string{authorizationMetadataKey: "Bearer " + c.token}, nil
+}
+
+func (c *perRPCCredentials) RequireTransportSecurity() bool {
+	return !c.insecure
+}
@@ -0,0 +1,123 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authorizationMetadataKey gRPC metadata 的 key 均为小写
+const authorizationMetadataKey = "authorization"
+
+// GRPCOption is a grpc interceptor option
+type GRPCOption func(*grpcAuth)
+
+// WithAnonymousMethods let calls of the full methods (e.g. "/grpc.health.v1.Health/Check") without token through,
+// invalid tokens are still rejected
+func WithAnonymousMethods(methods ...string) GRPCOption {
+	return func(a *grpcAuth) {
+		for _, m := range methods {
+			a.anonymous[m] = true
+		}
+	}
+}
+
+// grpcAuth 拦截器共用的认证逻辑
+type grpcAuth struct {
+	verify    func(tokenStr string) (any, error)
+	anonymous map[string]bool
+}
+
+func newGRPCAuth[T jwt.Claims](v *ClaimsVerifier[T], opts []GRPCOption) *grpcAuth {
+	a := &grpcAuth{
+		verify: func(tokenStr string) (any, error) {
+			return v.Verify(tokenStr)
+		},
+		anonymous: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// authenticate 校验 authorization metadata，返回携带 claims 的 ctx
+func (a *grpcAuth) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	var auth string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(authorizationMetadataKey); len(values) > 0 {
+			auth = values[0]
+		}
+	}
+
+	tokenStr, err := parseBearer(auth)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if tokenStr == "" {
+		if a.anonymous[fullMethod] {
+			return ctx, nil
+		}
+		return nil, status.Error(codes.Unauthenticated, ErrMissingToken.Error())
+	}
+
+	claims, err := a.verify(tokenStr)
+	if err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			return nil, status.Error(codes.Unauthenticated, tokenErrorDescription(err))
+		}
+		// 吊销存储等依赖故障
+		return nil, status.Error(codes.Internal, "token verification failed")
+	}
+	return context.WithValue(ctx, claimsContextKey{}, claims), nil
+}
+
+// NewUnaryServerInterceptor new a unary server interceptor verifying the "authorization: Bearer <token>" metadata with v,
+// the claims are stored in the context, see ClaimsFromContext. 失败时返回 codes.Unauthenticated
+func NewUnaryServerInterceptor[T jwt.Claims](v *ClaimsVerifier[T], opts ...GRPCOption) grpc.UnaryServerInterceptor {
+	a := newGRPCAuth(v, opts)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// NewStreamServerInterceptor new a stream server interceptor, see NewUnaryServerInterceptor
+func NewStreamServerInterceptor[T jwt.Claims](v *ClaimsVerifier[T], opts ...GRPCOption) grpc.StreamServerInterceptor {
+	a := newGRPCAuth(v, opts)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// NewTokenUnaryServerInterceptor new a unary server interceptor for TokenVerifier, see TokenInfoFromContext
+func NewTokenUnaryServerInterceptor(v *TokenVerifier, opts ...GRPCOption) grpc.UnaryServerInterceptor {
+	return NewUnaryServerInterceptor(v.claims, opts...)
+}
+
+// NewTokenStreamServerInterceptor new a stream server interceptor for TokenVerifier, see TokenInfoFromContext
+func NewTokenStreamServerInterceptor(v *TokenVerifier, opts ...GRPCOption) grpc.StreamServerInterceptor {
+	return NewStreamServerInterceptor(v.claims, opts...)
+}
+
+// authServerStream 替换 stream 的 ctx
+type authServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authServerStream) Context() context.Context {
+	return s.ctx
+}
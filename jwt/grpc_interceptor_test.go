@@ -0,0 +1,202 @@
+package jwt
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RunzhiZhao/go-mstoolkit/jwt/jwttest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// authHealthServer 记录 ctx 中的 TokenInfo
+type authHealthServer struct {
+	*health.Server
+	userID atomic.Uint32
+}
+
+func (s *authHealthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if info, ok := TokenInfoFromContext(ctx); ok {
+		s.userID.Store(uint32(info.UserID))
+	}
+	return s.Server.Check(ctx, req)
+}
+
+func (s *authHealthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	if info, ok := TokenInfoFromContext(stream.Context()); ok {
+		s.userID.Store(uint32(info.UserID))
+	}
+	return s.Server.Watch(req, stream)
+}
+
+func newTestGRPCServer(t *testing.T, v *TokenVerifier, opts ...GRPCOption) (*authHealthServer, func(opts ...grpc.DialOption) healthpb.HealthClient) {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(NewTokenUnaryServerInterceptor(v, opts...)),
+		grpc.StreamInterceptor(NewTokenStreamServerInterceptor(v, opts...)),
+	)
+	hs := &authHealthServer{Server: health.NewServer()}
+	healthpb.RegisterHealthServer(srv, hs)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	return hs, func(opts ...grpc.DialOption) healthpb.HealthClient {
+		opts = append(opts,
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return healthpb.NewHealthClient(conn)
+	}
+}
+
+func TestGRPCInterceptors(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	g, err := NewTokenGenerator(SigningMethodEdDSA, keys[SigningMethodEdDSA].privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewTokenVerifier(SigningMethodEdDSA, keys[SigningMethodEdDSA].publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs, dial := newTestGRPCServer(t, v)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 带凭证的 unary 与 stream 调用
+	client := dial(grpc.WithPerRPCCredentials(NewGeneratorPerRPCCredentials(g, TokenInfo{UserID: 5}, WithInsecureTransport())))
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if got := hs.userID.Load(); got != 5 {
+		t.Errorf("unary user id = %d, want 5", got)
+	}
+	hs.userID.Store(0)
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Watch().Recv() error = %v", err)
+	}
+	if got := hs.userID.Load(); got != 5 {
+		t.Errorf("stream user id = %d, want 5", got)
+	}
+
+	// 未携带或携带无效 token
+	anonymous := dial()
+	if _, err := anonymous.Check(ctx, &healthpb.HealthCheckRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Check() without token code = %v, want %v", status.Code(err), codes.Unauthenticated)
+	}
+	stream, err = anonymous.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Watch() without token code = %v, want %v", status.Code(err), codes.Unauthenticated)
+	}
+	badCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer abc")
+	_, err = anonymous.Check(badCtx, &healthpb.HealthCheckRequest{})
+	if s, _ := status.FromError(err); s.Code() != codes.Unauthenticated || s.Message() != "token is malformed" {
+		t.Errorf("Check() invalid token status = %v", s)
+	}
+}
+
+func TestGRPCInterceptors_AnonymousMethods(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	v, err := NewTokenVerifier(SigningMethodEdDSA, keys[SigningMethodEdDSA].publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, dial := newTestGRPCServer(t, v, WithAnonymousMethods(healthpb.Health_Check_FullMethodName))
+	client := dial()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Errorf("Check() anonymous error = %v", err)
+	}
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Watch() code = %v, want %v", status.Code(err), codes.Unauthenticated)
+	}
+}
+
+func TestPerRPCCredentials_Refresh(t *testing.T) {
+	var fetches int
+	expiresAt := time.Now().Add(30 * time.Second)
+	c := NewPerRPCCredentials(func(context.Context) (string, time.Time, error) {
+		fetches++
+		return "token", expiresAt, nil
+	}, WithRefreshBefore(time.Minute))
+	call := func() {
+		md, err := c.GetRequestMetadata(context.Background())
+		if err != nil || md["authorization"] != "Bearer token" {
+			t.Fatalf("GetRequestMetadata() = %v, %v", md, err)
+		}
+	}
+
+	// 缓存的 token 即将过期，每次都重新获取
+	call()
+	call()
+	if fetches != 2 {
+		t.Errorf("fetches = %d, want 2", fetches)
+	}
+
+	expiresAt = time.Now().Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		call()
+	}
+	if fetches != 3 {
+		t.Errorf("fetches = %d, want 3", fetches)
+	}
+	if !c.RequireTransportSecurity() {
+		t.Errorf("RequireTransportSecurity() = false, want true")
+	}
+}
+
+func TestGeneratorPerRPCCredentials_Clock(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	// 生成器的时钟远早于真实时间，过期判断必须使用同一个时钟
+	clock := jwttest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	g, err := NewTokenGenerator(SigningMethodHS256, keys[SigningMethodHS256].privateKey,
+		WithExpires(10*time.Minute), WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewGeneratorPerRPCCredentials(g, TokenInfo{UserID: 1}, WithRefreshBefore(time.Minute))
+	token := func() string {
+		md, err := c.GetRequestMetadata(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return md["authorization"]
+	}
+
+	first := token()
+	clock.Advance(8 * time.Minute)
+	if got := token(); got != first {
+		t.Error("token refreshed before refresh-before its expiry")
+	}
+	clock.Advance(time.Minute)
+	if got := token(); got == first {
+		t.Error("token not refreshed within refresh-before its expiry")
+	}
+}
@@ -0,0 +1,253 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrMissingToken 请求中没有 token
+	ErrMissingToken = errors.New("missing token")
+	// ErrInvalidRequest 请求中的 token 格式不正确，例如 Authorization 头缺少 token
+	ErrInvalidRequest = errors.New("invalid authorization request")
+)
+
+// TokenSource extracts the token from a request, returns "" when the request carries no token
+type TokenSource func(r *http.Request) (string, error)
+
+// BearerToken read the token from the "Authorization: Bearer <token>" header (RFC 6750 2.1)
+func BearerToken(r *http.Request) (string, error) {
+	return parseBearer(r.Header.Get("Authorization"))
+}
+
+// parseBearer 解析 "Bearer <token>"，其他认证方式返回空 token
+func parseBearer(auth string) (string, error) {
+	if auth == "" {
+		return "", nil
+	}
+	scheme, token, _ := strings.Cut(auth, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		// 其他认证方式，视为没有 bearer token
+		return "", nil
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", ErrInvalidRequest
+	}
+	return token, nil
+}
+
+// HeaderToken read the raw token from header name
+func HeaderToken(name string) TokenSource {
+	return func(r *http.Request) (string, error) {
+		return r.Header.Get(name), nil
+	}
+}
+
+// CookieToken read the token from cookie name
+func CookieToken(name string) TokenSource {
+	return func(r *http.Request) (string, error) {
+		c, err := r.Cookie(name)
+		if err != nil {
+			return "", nil
+		}
+		return c.Value, nil
+	}
+}
+
+// QueryToken read the token from query parameter name, e.g. "access_token" (RFC 6750 2.3)
+func QueryToken(name string) TokenSource {
+	return func(r *http.Request) (string, error) {
+		return r.URL.Query().Get(name), nil
+	}
+}
+
+// MiddlewareOption is a http middleware option
+type MiddlewareOption func(*httpMiddleware)
+
+// WithTokenSources set where to read the token from, tried in order, default is BearerToken
+func WithTokenSources(sources ...TokenSource) MiddlewareOption {
+	return func(m *httpMiddleware) {
+		m.sources = sources
+	}
+}
+
+// WithRealm set the realm of the WWW-Authenticate header
+func WithRealm(realm string) MiddlewareOption {
+	return func(m *httpMiddleware) {
+		m.realm = realm
+	}
+}
+
+// WithAnonymous let requests without token through, invalid tokens are still rejected
+func WithAnonymous() MiddlewareOption {
+	return WithAnonymousIf(func(*http.Request) bool { return true })
+}
+
+// WithAnonymousIf let requests without token through when allow returns true, e.g. for public routes
+func WithAnonymousIf(allow func(r *http.Request) bool) MiddlewareOption {
+	return func(m *httpMiddleware) {
+		m.anonymous = allow
+	}
+}
+
+// WithErrorHandler replace the default RFC 6750 error response
+func WithErrorHandler(h func(w http.ResponseWriter, r *http.Request, err error)) MiddlewareOption {
+	return func(m *httpMiddleware) {
+		m.errorHandler = h
+	}
+}
+
+type httpMiddleware struct {
+	sources      []TokenSource
+	realm        string
+	anonymous    func(r *http.Request) bool
+	errorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+func newHTTPMiddleware(opts []MiddlewareOption) *httpMiddleware {
+	m := &httpMiddleware{
+		sources: []TokenSource{BearerToken},
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.errorHandler == nil {
+		m.errorHandler = m.writeError
+	}
+	return m
+}
+
+// NewHTTPMiddleware new a net/http middleware verifying the request token with v,
+// the claims are stored in the request context, see ClaimsFromContext.
+// 缺少 token 返回 401，token 格式错误返回 400 invalid_request，验证失败返回 401 invalid_token
+func NewHTTPMiddleware[T jwt.Claims](v *ClaimsVerifier[T], opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	m := newHTTPMiddleware(opts)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tokenStr, err := m.token(r)
+			if err != nil {
+				m.errorHandler(w, r, err)
+				return
+			}
+			if tokenStr == "" {
+				if m.anonymous != nil && m.anonymous(r) {
+					next.ServeHTTP(w, r)
+					return
+				}
+				m.errorHandler(w, r, ErrMissingToken)
+				return
+			}
+
+			claims, err := v.Verify(tokenStr)
+			if err != nil {
+				m.errorHandler(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(ContextWithClaims(r.Context(), claims)))
+		})
+	}
+}
+
+// NewTokenHTTPMiddleware new a net/http middleware for TokenVerifier, see NewHTTPMiddleware and TokenInfoFromContext
+func NewTokenHTTPMiddleware(v *TokenVerifier, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	return NewHTTPMiddleware(v.claims, opts...)
+}
+
+// token 依次尝试各个来源，返回第一个非空 token
+func (m *httpMiddleware) token(r *http.Request) (string, error) {
+	for _, source := range m.sources {
+		tokenStr, err := source(r)
+		if err != nil || tokenStr != "" {
+			return tokenStr, err
+		}
+	}
+	return "", nil
+}
+
+// writeError 按 RFC 6750 第 3 节返回错误
+func (m *httpMiddleware) writeError(w http.ResponseWriter, _ *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrMissingToken):
+		writeBearerError(w, m.realm, http.StatusUnauthorized, "", "", "")
+	case errors.Is(err, ErrInvalidRequest):
+		writeBearerError(w, m.realm, http.StatusBadRequest, "invalid_request", err.Error(), "")
+	case errors.Is(err, ErrInvalidToken):
+		writeBearerError(w, m.realm, http.StatusUnauthorized, "invalid_token", tokenErrorDescription(err), "")
+	case errors.Is(err, ErrPermissionDenied):
+		writeForbidden(w, m.realm, err)
+	default:
+		// 吊销存储等依赖故障
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// writeBearerError 写入 WWW-Authenticate: Bearer 头和对应的状态码，参数为空时省略
+func writeBearerError(w http.ResponseWriter, realm string, status int, code, description, scope string) {
+	var params []string
+	if realm != "" {
+		params = append(params, `realm="`+quoteAuthParam(realm)+`"`)
+	}
+	if code != "" {
+		params = append(params, `error="`+code+`"`)
+	}
+	if description != "" {
+		params = append(params, `error_description="`+quoteAuthParam(description)+`"`)
+	}
+	if scope != "" {
+		params = append(params, `scope="`+quoteAuthParam(scope)+`"`)
+	}
+
+	challenge := "Bearer"
+	if len(params) > 0 {
+		challenge += " " + strings.Join(params, ", ")
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, http.StatusText(status), status)
+}
+
+// tokenErrorDescription 返回不含内部细节的错误描述，例如 "token is expired"
+func tokenErrorDescription(err error) string {
+	var ve *VerificationError
+	if errors.As(err, &ve) {
+		err = ve.sentinel()
+	}
+	return strings.TrimPrefix(err.Error(), ErrInvalidToken.Error()+": ")
+}
+
+// quoteAuthParam 去掉 quoted-string 中不允许的字符（RFC 6750 限制为可打印 ASCII，且不含 " 和 \）
+func quoteAuthParam(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+type claimsContextKey struct{}
+
+// ContextWithClaims return a copy of ctx carrying claims
+func ContextWithClaims[T any](ctx context.Context, claims *T) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// ClaimsFromContext return the claims stored by the middleware, false when the request is anonymous
+// or T is not the claims type of the verifier
+func ClaimsFromContext[T any](ctx context.Context) (*T, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(*T)
+	return claims, ok
+}
+
+// TokenInfoFromContext return the token info stored by the middleware of a TokenVerifier
+func TokenInfoFromContext(ctx context.Context) (*TokenInfo, bool) {
+	claims, ok := ClaimsFromContext[TokenClaims](ctx)
+	if !ok {
+		return nil, false
+	}
+	return &claims.TokenInfo, true
+}
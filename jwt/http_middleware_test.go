@@ -0,0 +1,127 @@
+package jwt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestHTTPMiddleware(t *testing.T, opts ...MiddlewareOption) (http.Handler, *ClaimsGenerator[TokenClaims]) {
+	keys := newTestKeyPairs(t)
+	g, err := NewClaimsGenerator[TokenClaims](SigningMethodEdDSA, keys[SigningMethodEdDSA].privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewTokenVerifier(SigningMethodEdDSA, keys[SigningMethodEdDSA].publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewTokenHTTPMiddleware(v, opts...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info, ok := TokenInfoFromContext(r.Context())
+		if !ok {
+			_, _ = w.Write([]byte("anonymous"))
+			return
+		}
+		_, _ = w.Write([]byte(strconv.Itoa(int(info.UserID))))
+	}))
+	return h, g
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	h, g := newTestHTTPMiddleware(t, WithRealm("api"))
+	valid, err := g.Generate(TokenClaims{TokenInfo: TokenInfo{UserID: 7}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := g.Generate(TokenClaims{RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantBody      string
+		wantChallenge string
+	}{
+		{name: "valid", authorization: "Bearer " + valid, wantStatus: http.StatusOK, wantBody: "7"},
+		{name: "scheme is case insensitive", authorization: "bearer " + valid, wantStatus: http.StatusOK, wantBody: "7"},
+		{name: "missing", wantStatus: http.StatusUnauthorized, wantChallenge: `Bearer realm="api"`},
+		{name: "other scheme", authorization: "Basic dXNlcjpwYXNz", wantStatus: http.StatusUnauthorized, wantChallenge: `Bearer realm="api"`},
+		{name: "empty bearer", authorization: "Bearer ", wantStatus: http.StatusBadRequest,
+			wantChallenge: `Bearer realm="api", error="invalid_request", error_description="invalid authorization request"`},
+		{name: "expired", authorization: "Bearer " + expired, wantStatus: http.StatusUnauthorized,
+			wantChallenge: `Bearer realm="api", error="invalid_token", error_description="token is expired"`},
+		{name: "malformed", authorization: "Bearer abc", wantStatus: http.StatusUnauthorized,
+			wantChallenge: `Bearer realm="api", error="invalid_token", error_description="token is malformed"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+			if got := w.Header().Get("WWW-Authenticate"); got != tt.wantChallenge {
+				t.Errorf("WWW-Authenticate = %q, want %q", got, tt.wantChallenge)
+			}
+		})
+	}
+}
+
+func TestHTTPMiddleware_TokenSources(t *testing.T) {
+	h, g := newTestHTTPMiddleware(t, WithTokenSources(BearerToken, CookieToken("session"), QueryToken("access_token")))
+	valid, err := g.Generate(TokenClaims{TokenInfo: TokenInfo{UserID: 9}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cookie := httptest.NewRequest(http.MethodGet, "/", nil)
+	cookie.AddCookie(&http.Cookie{Name: "session", Value: valid})
+	query := httptest.NewRequest(http.MethodGet, "/?access_token="+valid, nil)
+
+	for name, r := range map[string]*http.Request{"cookie": cookie, "query": query} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusOK || w.Body.String() != "9" {
+			t.Errorf("%s: status = %d, body = %q", name, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestHTTPMiddleware_Anonymous(t *testing.T) {
+	h, _ := newTestHTTPMiddleware(t, WithAnonymousIf(func(r *http.Request) bool {
+		return r.URL.Path == "/public"
+	}))
+
+	for path, wantStatus := range map[string]int{"/public": http.StatusOK, "/private": http.StatusUnauthorized} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != wantStatus {
+			t.Errorf("%s: status = %d, want %d", path, w.Code, wantStatus)
+		}
+	}
+
+	// 允许匿名的路由上，无效 token 仍然被拒绝
+	r := httptest.NewRequest(http.MethodGet, "/public", nil)
+	r.Header.Set("Authorization", "Bearer abc")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("invalid token on anonymous route: status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,151 @@
+package jwt
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	maxIntrospectionRequestSize = 64 << 10
+)
+
+// ClientAuthenticator authenticates the client calling the introspection endpoint,
+// the form of r is already parsed. Returns false to reject the request
+type ClientAuthenticator func(r *http.Request) bool
+
+// ClientSecrets authenticate clients by the client_id and client_secret sent with HTTP Basic (client_secret_basic)
+// or in the form body (client_secret_post), secrets maps client_id to client_secret
+func ClientSecrets(secrets map[string]string) ClientAuthenticator {
+	digests := make(map[string][sha256.Size]byte, len(secrets))
+	for id, secret := range secrets {
+		digests[id] = sha256.Sum256([]byte(secret))
+	}
+	return func(r *http.Request) bool {
+		id, secret, ok := clientCredentials(r)
+		if !ok {
+			return false
+		}
+		want, known := digests[id]
+		got := sha256.Sum256([]byte(secret))
+		// 比较摘要，避免泄露密钥长度；未知 client 同样做一次比较
+		return subtle.ConstantTimeCompare(got[:], want[:]) == 1 && known
+	}
+}
+
+// clientCredentials 读取 Basic 认证（RFC 6749 2.3.1 要求先做 form 编码）或 form 中的 client_id/client_secret
+func clientCredentials(r *http.Request) (id, secret string, ok bool) {
+	if id, secret, ok = r.BasicAuth(); ok {
+		var err1, err2 error
+		id, err1 = url.QueryUnescape(id)
+		secret, err2 = url.QueryUnescape(secret)
+		return id, secret, err1 == nil && err2 == nil && id != ""
+	}
+	id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	return id, secret, id != "" && secret != ""
+}
+
+type introspectionHandler struct {
+	verify       func(tokenStr string) (any, error)
+	authenticate ClientAuthenticator
+}
+
+// NewIntrospectionHandler new a RFC 7662 token introspection http.Handler verifying tokens with v,
+// including any revocation checks. Every request must pass authenticate, a nil authenticate rejects all requests.
+//
+//	mux.Handle("/oauth/introspect", jwt.NewIntrospectionHandler(v, jwt.ClientSecrets(map[string]string{"gateway": secret})))
+//
+// 有效 token 返回 claims 以及 active: true，scope 为空格分隔的 scopes；无效 token 只返回 active: false；
+// 吊销存储等依赖故障返回 500
+func NewIntrospectionHandler[T jwt.Claims](v *ClaimsVerifier[T], authenticate ClientAuthenticator) http.Handler {
+	return &introspectionHandler{
+		verify: func(tokenStr string) (any, error) {
+			return v.Verify(tokenStr)
+		},
+		authenticate: authenticate,
+	}
+}
+
+// NewTokenIntrospectionHandler new a RFC 7662 token introspection http.Handler for TokenVerifier, see NewIntrospectionHandler
+func NewTokenIntrospectionHandler(v *TokenVerifier, authenticate ClientAuthenticator) http.Handler {
+	return NewIntrospectionHandler(v.claims, authenticate)
+}
+
+func (h *introspectionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxIntrospectionRequestSize)
+	if err := r.ParseForm(); err != nil {
+		writeIntrospectionJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid_request"})
+		return
+	}
+	if h.authenticate == nil || !h.authenticate(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="introspection"`)
+		writeIntrospectionJSON(w, http.StatusUnauthorized, map[string]any{"error": "invalid_client"})
+		return
+	}
+	tokenStr := r.PostForm.Get("token")
+	if tokenStr == "" {
+		writeIntrospectionJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid_request"})
+		return
+	}
+
+	claims, err := h.verify(tokenStr)
+	if err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			// 不透露 token 无效的原因
+			writeIntrospectionJSON(w, http.StatusOK, map[string]any{"active": false})
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := introspectionResponse(claims)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeIntrospectionJSON(w, http.StatusOK, resp)
+}
+
+// introspectionResponse claims 的 JSON 字段加上 active 与 scope
+func introspectionResponse(claims any) (map[string]any, error) {
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	resp := make(map[string]any, len(m)+2)
+	for k, v := range m {
+		resp[k] = v
+	}
+	resp["active"] = true
+	if sc, ok := claims.(ScopedClaims); ok && len(sc.GetScopes()) > 0 {
+		resp["scope"] = strings.Join(sc.GetScopes(), " ")
+	}
+	return resp, nil
+}
+
+func writeIntrospectionJSON(w http.ResponseWriter, status int, v any) {
+	header := w.Header()
+	header.Set("Content-Type", "application/json")
+	header.Set("Cache-Control", "no-store")
+	header.Set("Pragma", "no-cache")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,257 @@
+package jwt
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrIntrospection 调用 introspection 端点失败，不包装 ErrInvalidToken
+	ErrIntrospection = errors.New("token introspection failed")
+	// ErrTokenInactive introspection 端点返回 active: false
+	ErrTokenInactive = fmt.Errorf("%w: token is not active", ErrInvalidToken)
+)
+
+const (
+	defaultIntrospectionTimeout     = 10 * time.Second
+	defaultIntrospectionCacheTTL    = time.Minute
+	maxIntrospectionBodySize        = 1 << 20
+	introspectionCacheSweepInterval = time.Minute
+)
+
+// IntrospectionOption is an introspection token verifier option
+type IntrospectionOption func(*introspectionClient)
+
+// WithIntrospectionHTTPClient set the http client used to call the introspection endpoint
+func WithIntrospectionHTTPClient(client *http.Client) IntrospectionOption {
+	return func(c *introspectionClient) {
+		c.client = client
+	}
+}
+
+// WithClientCredentials authenticate to the introspection endpoint with HTTP Basic (client_secret_basic)
+func WithClientCredentials(clientID, clientSecret string) IntrospectionOption {
+	return func(c *introspectionClient) {
+		c.clientID = clientID
+		c.clientSecret = clientSecret
+	}
+}
+
+// WithIntrospectionTimeout bound each call to the introspection endpoint, default is 10s
+func WithIntrospectionTimeout(timeout time.Duration) IntrospectionOption {
+	return func(c *introspectionClient) {
+		c.timeout = timeout
+	}
+}
+
+// WithIntrospectionCacheTTL limit how long an active result is cached, default is 1 minute and never beyond the token's exp,
+// 0 disables the cache. Inactive results are never cached.
+// 吊销最多在 ttl 之后生效；ttl 越长，端点的压力越小
+func WithIntrospectionCacheTTL(ttl time.Duration) IntrospectionOption {
+	return func(c *introspectionClient) {
+		c.maxCacheTTL = ttl
+	}
+}
+
+// WithIntrospectionVerifierOptions set the issuer/audience/leeway/max age/clock checks applied to the introspected claims
+func WithIntrospectionVerifierOptions(opts ...VerifierOption) IntrospectionOption {
+	return func(c *introspectionClient) {
+		c.verifierOpts = append(c.verifierOpts, opts...)
+	}
+}
+
+// introspectionClient 调用 RFC 7662 introspection 端点，active 的结果缓存 max cache ttl，不超过 token 的 exp
+type introspectionClient struct {
+	endpoint     string
+	client       *http.Client
+	clientID     string
+	clientSecret string
+	timeout      time.Duration
+	maxCacheTTL  time.Duration
+	verifierOpts []VerifierOption
+	clock        Clock
+
+	mu        sync.Mutex
+	entries   map[string]introspectionCacheEntry // key 为 token 的 sha256
+	lastSweep time.Time
+}
+
+type introspectionCacheEntry struct {
+	claims    []byte
+	expiresAt time.Time
+}
+
+// NewIntrospectionTokenVerifier new a token verifier asking the RFC 7662 introspection endpoint whether a token is active,
+// e.g. for opaque tokens or an auth server served by NewIntrospectionHandler.
+// 返回的 TokenVerifier 可以直接用于 HTTP/gRPC 中间件；active 的结果默认缓存 1 分钟（不超过 token 的 exp），
+// 吊销需要在缓存过期后才生效，见 WithIntrospectionCacheTTL；端点不可用或超时返回 ErrIntrospection
+func NewIntrospectionTokenVerifier(endpoint string, opts ...IntrospectionOption) (*TokenVerifier, error) {
+	v, err := NewIntrospectionClaimsVerifier[TokenClaims](endpoint, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenVerifier{claims: v}, nil
+}
+
+// NewIntrospectionClaimsVerifier new a token verifier for custom claims T backed by the introspection endpoint,
+// the response members are decoded into T, see NewIntrospectionTokenVerifier
+func NewIntrospectionClaimsVerifier[T jwt.Claims](endpoint string, opts ...IntrospectionOption) (*ClaimsVerifier[T], error) {
+	if u, err := url.Parse(endpoint); err != nil || !u.IsAbs() {
+		return nil, fmt.Errorf("%w: invalid endpoint %q", ErrIntrospection, endpoint)
+	}
+	c := &introspectionClient{
+		endpoint:    endpoint,
+		client:      &http.Client{Timeout: defaultIntrospectionTimeout},
+		timeout:     defaultIntrospectionTimeout,
+		maxCacheTTL: defaultIntrospectionCacheTTL,
+		entries:     make(map[string]introspectionCacheEntry),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	v := newVerifier(nil, nil, nil, c.verifierOpts)
+	if v.err != nil {
+		return nil, v.err
+	}
+	c.clock = v.clock
+	c.lastSweep = c.clock.Now()
+	v.introspection = c
+
+	return &ClaimsVerifier[T]{verifier: v}, nil
+}
+
+// parseIntrospected 通过 introspection 端点验证 token，再按 verifier option 校验返回的 claims
+func (v *verifier) parseIntrospected(tokenStr string, claims jwt.Claims) error {
+	ctx := context.Background()
+	if t := v.introspection.timeout; t > 0 {
+		// 自定义的 http.Client 可能没有设置超时
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t)
+		defer cancel()
+	}
+	data, err := v.introspection.introspect(ctx, tokenStr)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, claims); err != nil {
+		return newVerificationError(ReasonMalformed, err)
+	}
+	if err := v.checkTimes(claims); err != nil {
+		return err
+	}
+	return v.checkClaims(claims)
+}
+
+// introspect 返回 active token 的 claims JSON
+func (c *introspectionClient) introspect(ctx context.Context, tokenStr string) ([]byte, error) {
+	sum := sha256.Sum256([]byte(tokenStr))
+	key := hex.EncodeToString(sum[:])
+	if claims, ok := c.cached(key); ok {
+		return claims, nil
+	}
+
+	m, err := c.fetch(ctx, tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	var active bool
+	if err := json.Unmarshal(m["active"], &active); err != nil || !active {
+		return nil, newVerificationError(ReasonInactive, nil)
+	}
+	delete(m, "active")
+
+	// 其他实现只返回空格分隔的 scope，转换为 TokenInfo 的 scopes
+	var scope string
+	if _, ok := m["scopes"]; !ok && json.Unmarshal(m["scope"], &scope) == nil && scope != "" {
+		m["scopes"], _ = json.Marshal(strings.Fields(scope))
+	}
+	claims, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache(key, claims, m["exp"])
+	return claims, nil
+}
+
+func (c *introspectionClient) fetch(ctx context.Context, tokenStr string) (map[string]json.RawMessage, error) {
+	form := url.Values{"token": {tokenStr}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIntrospection, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.clientID != "" {
+		// RFC 6749 2.3.1 要求先做 form 编码
+		req.SetBasicAuth(url.QueryEscape(c.clientID), url.QueryEscape(c.clientSecret))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIntrospection, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrIntrospection, resp.StatusCode)
+	}
+
+	var m map[string]json.RawMessage
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxIntrospectionBodySize)).Decode(&m); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIntrospection, err)
+	}
+	return m, nil
+}
+
+func (c *introspectionClient) cached(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || !c.clock.Now().Before(e.expiresAt) {
+		return nil, false
+	}
+	return e.claims, true
+}
+
+// cache 缓存 max cache ttl，不超过 exp
+func (c *introspectionClient) cache(key string, claims []byte, exp json.RawMessage) {
+	now := c.clock.Now()
+	ttl := c.maxCacheTTL
+	var expiresAt jwt.NumericDate
+	if err := json.Unmarshal(exp, &expiresAt); err == nil {
+		if untilExp := expiresAt.Sub(now); untilExp < ttl {
+			ttl = untilExp
+		}
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.Sub(c.lastSweep) >= introspectionCacheSweepInterval {
+		for k, e := range c.entries {
+			if !now.Before(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastSweep = now
+	}
+	c.entries[key] = introspectionCacheEntry{claims: claims, expiresAt: now.Add(ttl)}
+}
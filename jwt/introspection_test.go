@@ -0,0 +1,343 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RunzhiZhao/go-mstoolkit/jwt/jwttest"
+)
+
+// newTestIntrospectionServer 使用 HS256 签发与验证，返回签发器、吊销存储、服务和请求计数
+func newTestIntrospectionServer(t *testing.T) (*TokenGenerator, RevocationStore, *httptest.Server, *atomic.Int32) {
+	t.Helper()
+	secret := bytes.Repeat([]byte{0x42}, 32)
+	g, err := NewTokenGenerator(SigningMethodHS256, secret, WithExpires(time.Hour), WithJTI(), WithIssuer("auth"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewMemoryRevocationStore()
+	v, err := NewTokenVerifier(SigningMethodHS256, secret, WithRevocationStore(store), WithRevocationCacheTTL(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewTokenIntrospectionHandler(v, ClientSecrets(map[string]string{"gateway": "s3cret&="}))
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		h.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return g, store, srv, &requests
+}
+
+func TestIntrospectionHandler(t *testing.T) {
+	g, store, srv, _ := newTestIntrospectionServer(t)
+	tokenStr, err := g.Generate(TokenInfo{UserID: 7, Scopes: []string{"read", "write"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	revoked, err := g.Generate(TokenInfo{UserID: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := NewClaimsVerifier[TokenClaims](SigningMethodHS256, bytes.Repeat([]byte{0x42}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := claims.Verify(revoked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RevokeToken(context.Background(), rc.ID, rc.ExpiresAt.Time); err != nil {
+		t.Fatal(err)
+	}
+
+	basic := func(r *http.Request) { r.SetBasicAuth("gateway", url.QueryEscape("s3cret&=")) }
+	tests := []struct {
+		name       string
+		method     string
+		form       url.Values
+		auth       func(r *http.Request)
+		wantStatus int
+		wantBody   map[string]any
+	}{
+		{name: "GET", method: http.MethodGet, auth: basic, wantStatus: http.StatusMethodNotAllowed},
+		{name: "no client auth", form: url.Values{"token": {tokenStr}}, wantStatus: http.StatusUnauthorized, wantBody: map[string]any{"error": "invalid_client"}},
+		{name: "wrong secret", form: url.Values{"token": {tokenStr}}, auth: func(r *http.Request) { r.SetBasicAuth("gateway", "wrong") }, wantStatus: http.StatusUnauthorized, wantBody: map[string]any{"error": "invalid_client"}},
+		{name: "unknown client", form: url.Values{"token": {tokenStr}}, auth: func(r *http.Request) { r.SetBasicAuth("other", "s3cret&=") }, wantStatus: http.StatusUnauthorized, wantBody: map[string]any{"error": "invalid_client"}},
+		{name: "missing token", auth: basic, wantStatus: http.StatusBadRequest, wantBody: map[string]any{"error": "invalid_request"}},
+		{name: "invalid token", form: url.Values{"token": {"a.b.c"}}, auth: basic, wantStatus: http.StatusOK, wantBody: map[string]any{"active": false}},
+		{name: "revoked token", form: url.Values{"token": {revoked}}, auth: basic, wantStatus: http.StatusOK, wantBody: map[string]any{"active": false}},
+		{name: "active token", form: url.Values{"token": {tokenStr}, "token_type_hint": {"access_token"}}, auth: basic, wantStatus: http.StatusOK,
+			wantBody: map[string]any{"active": true, "scope": "read write", "iss": "auth", "user_id": float64(7)}},
+		{name: "client_secret_post", form: url.Values{"token": {tokenStr}, "client_id": {"gateway"}, "client_secret": {"s3cret&="}}, wantStatus: http.StatusOK,
+			wantBody: map[string]any{"active": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			req, err := http.NewRequest(method, srv.URL, strings.NewReader(tt.form.Encode()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.auth != nil {
+				tt.auth(req)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantBody == nil {
+				return
+			}
+			if cc := resp.Header.Get("Cache-Control"); cc != "no-store" {
+				t.Errorf("Cache-Control = %q, want no-store", cc)
+			}
+			var body map[string]any
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			for k, want := range tt.wantBody {
+				if body[k] != want {
+					t.Errorf("%s = %v, want %v", k, body[k], want)
+				}
+			}
+			if body["active"] == false && len(body) != 1 {
+				t.Errorf("inactive response = %v, want only active", body)
+			}
+			if body["active"] == true && (body["exp"] == nil || body["jti"] == nil) {
+				t.Errorf("active response = %v, want exp and jti", body)
+			}
+		})
+	}
+}
+
+func TestIntrospectionVerifier(t *testing.T) {
+	g, store, srv, requests := newTestIntrospectionServer(t)
+	clock := jwttest.NewFakeClock(time.Now())
+	newVerifier := func(t *testing.T, opts ...IntrospectionOption) *TokenVerifier {
+		t.Helper()
+		opts = append([]IntrospectionOption{
+			WithClientCredentials("gateway", "s3cret&="),
+			WithIntrospectionVerifierOptions(WithVerifierClock(clock), WithExpectedIssuer("auth")),
+		}, opts...)
+		v, err := NewIntrospectionTokenVerifier(srv.URL, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	t.Run("cached for the default ttl", func(t *testing.T) {
+		v := newVerifier(t)
+		tokenStr, err := g.Generate(TokenInfo{UserID: 7, Scopes: []string{"read"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		requests.Store(0)
+		for i := 0; i < 3; i++ {
+			info, err := v.Verify(tokenStr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.UserID != 7 || len(info.Scopes) != 1 || info.Scopes[0] != "read" {
+				t.Errorf("Verify() = %+v", info)
+			}
+		}
+		if n := requests.Load(); n != 1 {
+			t.Errorf("introspection requests = %d, want 1", n)
+		}
+
+		clock.Advance(defaultIntrospectionCacheTTL)
+		defer clock.Advance(-defaultIntrospectionCacheTTL)
+		if _, err := v.Verify(tokenStr); err != nil {
+			t.Fatal(err)
+		}
+		if n := requests.Load(); n != 2 {
+			t.Errorf("introspection requests after default cache ttl = %d, want 2", n)
+		}
+	})
+
+	t.Run("cached until exp", func(t *testing.T) {
+		v := newVerifier(t, WithIntrospectionCacheTTL(24*time.Hour))
+		tokenStr, err := g.Generate(TokenInfo{UserID: 7})
+		if err != nil {
+			t.Fatal(err)
+		}
+		requests.Store(0)
+		if _, err := v.Verify(tokenStr); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(59 * time.Minute)
+		if _, err := v.Verify(tokenStr); err != nil {
+			t.Fatal(err)
+		}
+		if n := requests.Load(); n != 1 {
+			t.Errorf("introspection requests = %d, want 1", n)
+		}
+
+		clock.Advance(time.Minute)
+		defer clock.Advance(-time.Hour)
+		if _, err := v.Verify(tokenStr); ReasonOf(err) != ReasonExpired {
+			t.Errorf("Verify() after exp error = %v, want %v", err, ReasonExpired)
+		}
+	})
+
+	t.Run("revoked after cache ttl", func(t *testing.T) {
+		v := newVerifier(t, WithIntrospectionCacheTTL(time.Minute))
+		tokenStr, err := g.Generate(TokenInfo{UserID: 7})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := v.Verify(tokenStr); err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := NewClaimsVerifier[TokenClaims](SigningMethodHS256, bytes.Repeat([]byte{0x42}, 32))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rc, err := parsed.Verify(tokenStr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.RevokeToken(context.Background(), rc.ID, rc.ExpiresAt.Time); err != nil {
+			t.Fatal(err)
+		}
+
+		// 缓存期内仍然有效
+		if _, err := v.Verify(tokenStr); err != nil {
+			t.Errorf("Verify() within cache ttl error = %v", err)
+		}
+		clock.Advance(time.Minute)
+		_, err = v.Verify(tokenStr)
+		if !errors.Is(err, ErrTokenInactive) || !errors.Is(err, ErrInvalidToken) || ReasonOf(err) != ReasonInactive {
+			t.Errorf("Verify() after cache ttl error = %v, want %v", err, ErrTokenInactive)
+		}
+	})
+
+	t.Run("inactive not cached", func(t *testing.T) {
+		v := newVerifier(t)
+		requests.Store(0)
+		for i := 0; i < 2; i++ {
+			if _, err := v.Verify("a.b.c"); !errors.Is(err, ErrTokenInactive) {
+				t.Errorf("Verify() error = %v, want %v", err, ErrTokenInactive)
+			}
+		}
+		if n := requests.Load(); n != 2 {
+			t.Errorf("introspection requests = %d, want 2", n)
+		}
+	})
+
+	t.Run("wrong client credentials", func(t *testing.T) {
+		v := newVerifier(t, WithClientCredentials("gateway", "wrong"))
+		tokenStr, err := g.Generate(TokenInfo{UserID: 7})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := v.Verify(tokenStr); !errors.Is(err, ErrIntrospection) || errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Verify() error = %v, want %v", err, ErrIntrospection)
+		}
+	})
+
+	t.Run("issuer", func(t *testing.T) {
+		v := newVerifier(t, WithIntrospectionVerifierOptions(WithExpectedIssuer("other")))
+		tokenStr, err := g.Generate(TokenInfo{UserID: 7})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := v.Verify(tokenStr); ReasonOf(err) != ReasonInvalidIssuer {
+			t.Errorf("Verify() error = %v, want %v", err, ReasonInvalidIssuer)
+		}
+	})
+
+	t.Run("http middleware", func(t *testing.T) {
+		v := newVerifier(t)
+		tokenStr, err := g.Generate(TokenInfo{UserID: 9})
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := NewTokenHTTPMiddleware(v)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if info, ok := TokenInfoFromContext(r.Context()); !ok || info.UserID != 9 {
+				t.Errorf("TokenInfoFromContext() = %+v, %v", info, ok)
+			}
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+tokenStr)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want 200", rec.Code)
+		}
+	})
+
+	if _, err := NewIntrospectionTokenVerifier("/introspect"); !errors.Is(err, ErrIntrospection) {
+		t.Errorf("NewIntrospectionTokenVerifier(relative) error = %v, want %v", err, ErrIntrospection)
+	}
+}
+
+func TestIntrospectionVerifier_ScopeString(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Unix()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("token") != "opaque" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"active": false})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"active": true, "scope": "read write", "sub": "alice", "exp": exp})
+	}))
+	defer srv.Close()
+
+	v, err := NewIntrospectionClaimsVerifier[TokenClaims](srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := v.Verify("opaque")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "alice" || len(claims.Scopes) != 2 || claims.Scopes[1] != "write" || claims.ExpiresAt.Unix() != exp {
+		t.Errorf("Verify() = %+v", claims)
+	}
+}
+
+func TestIntrospectionVerifier_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 端点一直不响应，直到测试结束
+		<-release
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	// 自定义的 http.Client 没有超时，由 WithIntrospectionTimeout 限制
+	v, err := NewIntrospectionTokenVerifier(srv.URL,
+		WithIntrospectionHTTPClient(&http.Client{}), WithIntrospectionTimeout(20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if _, err := v.Verify("a.b.c"); !errors.Is(err, ErrIntrospection) || errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify() error = %v, want %v", err, ErrIntrospection)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Verify() took %v, want bounded by the introspection timeout", elapsed)
+	}
+}
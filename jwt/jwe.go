@@ -0,0 +1,163 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	jose "github.com/go-jose/go-jose/v3"
+)
+
+var (
+	ErrInvalidEncryptionKey = errors.New("invalid encryption key")
+	// ErrDecryptionFailed token 不是预期的 JWE，或解密失败
+	ErrDecryptionFailed = fmt.Errorf("%w: decryption failed", ErrInvalidToken)
+)
+
+// KeyAlgorithm is a JWE key management algorithm, the content is always encrypted with A256GCM
+type KeyAlgorithm string
+
+const (
+	// KeyAlgorithmDir 直接使用 32 字节的共享密钥
+	KeyAlgorithmDir KeyAlgorithm = "dir"
+	// KeyAlgorithmRSAOAEP256 使用接收方的 RSA 公钥加密内容密钥
+	KeyAlgorithmRSAOAEP256 KeyAlgorithm = "RSA-OAEP-256"
+	// KeyAlgorithmECDHES 与接收方的 EC 公钥（P-256/P-384/P-521）协商内容密钥
+	KeyAlgorithmECDHES KeyAlgorithm = "ECDH-ES"
+
+	jweContentEncryption = "A256GCM"
+)
+
+// WithEncryption encrypt the signed token as a compact JWE (nested JWT), key is the 32 bytes secret for
+// KeyAlgorithmDir, or the PEM public key of the recipient for KeyAlgorithmRSAOAEP256/KeyAlgorithmECDHES
+func WithEncryption(alg KeyAlgorithm, key []byte) Option {
+	return func(g *generator) {
+		enc, err := newJWEEncrypter(alg, key)
+		if err != nil {
+			g.err = err
+			return
+		}
+		g.encrypter = enc
+	}
+}
+
+// WithDecryption require tokens to be nested JWTs encrypted with alg, they are decrypted before verification.
+// key is the 32 bytes secret for KeyAlgorithmDir, or the PEM private key for KeyAlgorithmRSAOAEP256/KeyAlgorithmECDHES
+func WithDecryption(alg KeyAlgorithm, key []byte) VerifierOption {
+	return func(v *verifier) {
+		dec, err := newJWEDecrypter(alg, key)
+		if err != nil {
+			v.err = err
+			return
+		}
+		v.decrypter = dec
+	}
+}
+
+func newJWEEncrypter(alg KeyAlgorithm, key []byte) (jose.Encrypter, error) {
+	var recipientKey interface{}
+	switch alg {
+	case KeyAlgorithmDir:
+		if len(key) != 32 {
+			return nil, ErrInvalidEncryptionKey
+		}
+		recipientKey = key
+	case KeyAlgorithmRSAOAEP256:
+		pk, err := ParsePublicKey(key)
+		if _, ok := pk.(*rsa.PublicKey); err != nil || !ok {
+			return nil, ErrInvalidEncryptionKey
+		}
+		recipientKey = pk
+	case KeyAlgorithmECDHES:
+		pk, err := ParsePublicKey(key)
+		if _, ok := pk.(*ecdsa.PublicKey); err != nil || !ok {
+			return nil, ErrInvalidEncryptionKey
+		}
+		recipientKey = pk
+	default:
+		return nil, ErrInvalidEncryptionKey
+	}
+
+	opts := (&jose.EncrypterOptions{}).WithType("JWT").WithContentType("JWT")
+	enc, err := jose.NewEncrypter(jose.A256GCM, jose.Recipient{Algorithm: jose.KeyAlgorithm(alg), Key: recipientKey}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncryptionKey, err)
+	}
+	return enc, nil
+}
+
+// jweDecrypter 只接受指定 alg 与 A256GCM 的 compact JWE
+type jweDecrypter struct {
+	alg KeyAlgorithm
+	key interface{}
+}
+
+func newJWEDecrypter(alg KeyAlgorithm, key []byte) (*jweDecrypter, error) {
+	d := &jweDecrypter{alg: alg}
+	switch alg {
+	case KeyAlgorithmDir:
+		if len(key) != 32 {
+			return nil, ErrInvalidEncryptionKey
+		}
+		d.key = key
+	case KeyAlgorithmRSAOAEP256:
+		pk, err := ParsePrivateKey(key)
+		if _, ok := pk.(*rsa.PrivateKey); err != nil || !ok {
+			return nil, ErrInvalidEncryptionKey
+		}
+		d.key = pk
+	case KeyAlgorithmECDHES:
+		pk, err := ParsePrivateKey(key)
+		if _, ok := pk.(*ecdsa.PrivateKey); err != nil || !ok {
+			return nil, ErrInvalidEncryptionKey
+		}
+		d.key = pk
+	default:
+		return nil, ErrInvalidEncryptionKey
+	}
+	return d, nil
+}
+
+// decrypt 解密 compact JWE，返回内层的 JWS
+func (d *jweDecrypter) decrypt(tokenStr string) (string, error) {
+	parts := strings.Split(tokenStr, ".")
+	if len(parts) != 5 {
+		return "", newVerificationError(ReasonDecryptionFailed, errors.New("token is not a compact JWE"))
+	}
+
+	// 解密前先校验头部的 alg/enc，避免被替换为其他算法
+	var header struct {
+		Alg string `json:"alg"`
+		Enc string `json:"enc"`
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || json.Unmarshal(raw, &header) != nil {
+		return "", newVerificationError(ReasonDecryptionFailed, errors.New("malformed JWE header"))
+	}
+	if header.Alg != string(d.alg) || header.Enc != jweContentEncryption {
+		return "", newVerificationError(ReasonDecryptionFailed, fmt.Errorf("unexpected JWE algorithm %s/%s", header.Alg, header.Enc))
+	}
+
+	obj, err := jose.ParseEncrypted(tokenStr)
+	if err != nil {
+		return "", newVerificationError(ReasonDecryptionFailed, err)
+	}
+	payload, err := obj.Decrypt(d.key)
+	if err != nil {
+		return "", newVerificationError(ReasonDecryptionFailed, err)
+	}
+	return string(payload), nil
+}
+
+// encrypt 将签名后的 token 加密为 compact JWE
+func encrypt(enc jose.Encrypter, signed string) (string, error) {
+	obj, err := enc.Encrypt([]byte(signed))
+	if err != nil {
+		return "", err
+	}
+	return obj.CompactSerialize()
+}
@@ -0,0 +1,119 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNestedJWE(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	secret := bytes.Repeat([]byte{0x42}, 32)
+
+	tests := []struct {
+		name       string
+		alg        KeyAlgorithm
+		encryptKey []byte
+		decryptKey []byte
+	}{
+		{name: "dir", alg: KeyAlgorithmDir, encryptKey: secret, decryptKey: secret},
+		{name: "RSA-OAEP-256", alg: KeyAlgorithmRSAOAEP256, encryptKey: keys[SigningMethodRS256].publicKey, decryptKey: keys[SigningMethodRS256].privateKey},
+		{name: "ECDH-ES P-256", alg: KeyAlgorithmECDHES, encryptKey: keys[SigningMethodES256].publicKey, decryptKey: keys[SigningMethodES256].privateKey},
+		{name: "ECDH-ES P-521", alg: KeyAlgorithmECDHES, encryptKey: keys[SigningMethodES512].publicKey, decryptKey: keys[SigningMethodES512].privateKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewClaimsGenerator[testTenantClaims](SigningMethodEdDSA, keys[SigningMethodEdDSA].privateKey,
+				WithEncryption(tt.alg, tt.encryptKey))
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := NewClaimsVerifier[testTenantClaims](SigningMethodEdDSA, keys[SigningMethodEdDSA].publicKey,
+				WithDecryption(tt.alg, tt.decryptKey))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tokenStr, err := g.Generate(testTenantClaims{TenantID: "acme-pii"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Count(tokenStr, ".") != 4 {
+				t.Fatalf("token is not a compact JWE: %s", tokenStr)
+			}
+			got, err := v.Verify(tokenStr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.TenantID != "acme-pii" {
+				t.Errorf("TenantID = %q, want %q", got.TenantID, "acme-pii")
+			}
+
+			// 未配置解密的 verifier 无法解析
+			plain, err := NewClaimsVerifier[testTenantClaims](SigningMethodEdDSA, keys[SigningMethodEdDSA].publicKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := plain.Verify(tokenStr); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Verify() without decryption error = %v", err)
+			}
+		})
+	}
+}
+
+func TestNestedJWE_Rejects(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		t.Fatal(err)
+	}
+	other := make([]byte, 32)
+
+	v, err := NewTokenVerifier(SigningMethodHS256, keys[SigningMethodHS256].publicKey, WithDecryption(KeyAlgorithmDir, secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(opts ...Option) string {
+		g, err := NewTokenGenerator(SigningMethodHS256, keys[SigningMethodHS256].privateKey, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokenStr, err := g.Generate(TokenInfo{UserID: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tokenStr
+	}
+
+	tests := map[string]string{
+		"plain JWS":   sign(),
+		"wrong key":   sign(WithEncryption(KeyAlgorithmDir, other)),
+		"wrong alg":   sign(WithEncryption(KeyAlgorithmRSAOAEP256, keys[SigningMethodRS256].publicKey)),
+		"not a token": "a.b.c.d.e",
+	}
+	for name, tokenStr := range tests {
+		if _, err := v.Verify(tokenStr); !errors.Is(err, ErrDecryptionFailed) || ReasonOf(err) != ReasonDecryptionFailed {
+			t.Errorf("%s: Verify() error = %v, want %v", name, err, ErrDecryptionFailed)
+		}
+	}
+}
+
+func TestNestedJWE_InvalidKey(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	if _, err := NewTokenGenerator(SigningMethodHS256, keys[SigningMethodHS256].privateKey,
+		WithEncryption(KeyAlgorithmDir, []byte("short"))); !errors.Is(err, ErrInvalidEncryptionKey) {
+		t.Errorf("NewTokenGenerator() error = %v, want %v", err, ErrInvalidEncryptionKey)
+	}
+	if _, err := NewTokenVerifier(SigningMethodHS256, keys[SigningMethodHS256].publicKey,
+		WithDecryption(KeyAlgorithmECDHES, keys[SigningMethodRS256].privateKey)); !errors.Is(err, ErrInvalidEncryptionKey) {
+		t.Errorf("NewTokenVerifier() error = %v, want %v", err, ErrInvalidEncryptionKey)
+	}
+
+	// 使用密钥环的 generator 在签发时返回错误
+	ring, _ := newTestRing(t, "a")
+	if _, err := NewTokenGeneratorWithKeyRing(ring, WithEncryption("A128KW", nil)).Generate(TokenInfo{}); !errors.Is(err, ErrInvalidEncryptionKey) {
+		t.Errorf("Generate() error = %v, want %v", err, ErrInvalidEncryptionKey)
+	}
+}
@@ -0,0 +1,239 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+var (
+	ErrUnsupportedKeyType = errors.New("unsupported key type")
+	ErrInvalidJWK         = errors.New("invalid jwk")
+)
+
+// JWK RFC 7517 JSON Web Key，只包含公钥部分
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	// OKP / EC
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+}
+
+// JWKSet RFC 7517 JWK Set
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKSSource 可以导出 JWK Set 的密钥来源，KeyRing、TokenGenerator 和 ClaimsGenerator 都实现了该接口
+type JWKSSource interface {
+	JWKS() (JWKSet, error)
+}
+
+// JWKS 导出密钥环中 active 和 verify-only 密钥的公钥，HMAC 密钥不会被导出
+func (r *KeyRing) JWKS() (JWKSet, error) {
+	set := r.set.Load()
+	jwks := JWKSet{Keys: []JWK{}}
+	for _, key := range set.verifier {
+		if key.parser.signingMethod.isHMAC() {
+			continue
+		}
+		jwk, err := newJWK(key.parser.publicKey, key.id, key.parser.signingMethod)
+		if err != nil {
+			return JWKSet{}, err
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	// 按 kid 排序，保证输出稳定（便于 ETag 缓存）
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].Kid < jwks.Keys[j].Kid
+	})
+	return jwks, nil
+}
+
+// JWKS 导出签名密钥的公钥；HMAC 密钥不会被导出。
+// 未设置 WithKeyID 时 kid 使用 RFC 7638 thumbprint
+func (g *TokenGenerator) JWKS() (JWKSet, error) {
+	return g.claims.JWKS()
+}
+
+// JWKS 导出签名密钥的公钥；HMAC 密钥不会被导出。
+// 未设置 WithKeyID 时 kid 使用 RFC 7638 thumbprint
+func (g *generator) JWKS() (JWKSet, error) {
+	if g.keyRing != nil {
+		return g.keyRing.JWKS()
+	}
+
+	jwks := JWKSet{Keys: []JWK{}}
+	method := SigningMethod(g.signer.signingMethod.Alg())
+	if method.isHMAC() {
+		return jwks, nil
+	}
+	priv, ok := g.signer.privateKey.(crypto.Signer)
+	if !ok {
+		return JWKSet{}, ErrUnsupportedKeyType
+	}
+	jwk, err := newJWK(priv.Public(), g.kid, method)
+	if err != nil {
+		return JWKSet{}, err
+	}
+	if jwk.Kid == "" {
+		jwk.Kid = jwk.Thumbprint()
+	}
+	jwks.Keys = append(jwks.Keys, jwk)
+	return jwks, nil
+}
+
+// newJWK 将公钥转换为 JWK
+func newJWK(publicKey interface{}, kid string, method SigningMethod) (JWK, error) {
+	jwk := JWK{
+		Kid: kid,
+		Alg: string(method),
+		Use: "sig",
+	}
+
+	switch pk := publicKey.(type) {
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pk)
+
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pk.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pk.E)).Bytes())
+
+	case *ecdsa.PublicKey:
+		jwk.Kty = "EC"
+		jwk.Crv = pk.Curve.Params().Name
+		size := (pk.Curve.Params().BitSize + 7) / 8
+		jwk.X = base64.RawURLEncoding.EncodeToString(pk.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(pk.Y.FillBytes(make([]byte, size)))
+
+	default:
+		return JWK{}, ErrUnsupportedKeyType
+	}
+	return jwk, nil
+}
+
+// Thumbprint RFC 7638 JWK thumbprint (SHA-256)
+func (k JWK) Thumbprint() string {
+	// 按 RFC 7638 只保留必需成员，且按字典序排列
+	var members interface{}
+	switch k.Kty {
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{k.Crv, k.Kty, k.X}
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{k.E, k.Kty, k.N}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{k.Crv, k.Kty, k.X, k.Y}
+	}
+	b, _ := json.Marshal(members)
+	sum := sha256.Sum256(b)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// isHMAC 对称密钥不能公开
+func (m SigningMethod) isHMAC() bool {
+	return m == SigningMethodHS256 || m == SigningMethodHS384 || m == SigningMethodHS512
+}
+
+// PublicKey 解析 JWK 中的公钥，返回 ed25519.PublicKey、*rsa.PublicKey 或 *ecdsa.PublicKey
+func (k JWK) PublicKey() (interface{}, error) {
+	switch k.Kty {
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("%w: curve %s", ErrUnsupportedKeyType, k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: bad x", ErrInvalidJWK)
+		}
+		return ed25519.PublicKey(x), nil
+
+	case "RSA":
+		n, err1 := base64.RawURLEncoding.DecodeString(k.N)
+		e, err2 := base64.RawURLEncoding.DecodeString(k.E)
+		if err1 != nil || err2 != nil || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("%w: bad n or e", ErrInvalidJWK)
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("%w: curve %s", ErrUnsupportedKeyType, k.Crv)
+		}
+		x, err1 := base64.RawURLEncoding.DecodeString(k.X)
+		y, err2 := base64.RawURLEncoding.DecodeString(k.Y)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("%w: bad x or y", ErrInvalidJWK)
+		}
+		pk := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pk.X, pk.Y) {
+			return nil, fmt.Errorf("%w: point not on curve", ErrInvalidJWK)
+		}
+		return pk, nil
+	}
+	return nil, fmt.Errorf("%w: kty %s", ErrUnsupportedKeyType, k.Kty)
+}
+
+// signingMethod 返回 JWK 对应的签名方法，未声明 alg 时按密钥类型推断（RSA 无法推断）
+func (k JWK) signingMethod() (SigningMethod, error) {
+	if k.Alg != "" {
+		m := SigningMethod(k.Alg)
+		if m.jwtMethod() == nil || m.isHMAC() {
+			return "", fmt.Errorf("%w: alg %s", ErrUnsupportedKeyType, k.Alg)
+		}
+		return m, nil
+	}
+	switch {
+	case k.Kty == "OKP":
+		return SigningMethodEdDSA, nil
+	case k.Kty == "EC" && k.Crv == "P-256":
+		return SigningMethodES256, nil
+	case k.Kty == "EC" && k.Crv == "P-384":
+		return SigningMethodES384, nil
+	case k.Kty == "EC" && k.Crv == "P-521":
+		return SigningMethodES512, nil
+	}
+	return "", fmt.Errorf("%w: missing alg", ErrInvalidJWK)
+}
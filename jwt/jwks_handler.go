@@ -0,0 +1,88 @@
+package jwt
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// JWKSPath 约定的 JWK Set 发布路径
+	JWKSPath = "/.well-known/jwks.json"
+
+	defaultJWKSMaxAge = 5 * time.Minute
+)
+
+// JWKSHandlerOption is a jwks handler option
+type JWKSHandlerOption func(*jwksHandler)
+
+// WithJWKSMaxAge set Cache-Control max-age of the response
+func WithJWKSMaxAge(maxAge time.Duration) JWKSHandlerOption {
+	return func(h *jwksHandler) {
+		h.maxAge = maxAge
+	}
+}
+
+type jwksHandler struct {
+	source JWKSSource
+	maxAge time.Duration
+}
+
+// NewJWKSHandler new a http.Handler serving the JWK Set of source,
+// usually mounted at JWKSPath:
+//
+//	mux.Handle(jwt.JWKSPath, jwt.NewJWKSHandler(ring))
+//
+// 每次请求都重新导出，密钥环替换后立即生效；响应带 ETag，支持 If-None-Match
+func NewJWKSHandler(source JWKSSource, opts ...JWKSHandlerOption) http.Handler {
+	h := &jwksHandler{
+		source: source,
+		maxAge: defaultJWKSMaxAge,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func (h *jwksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	jwks, err := h.source.JWKS()
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	body, err := json.Marshal(jwks)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+
+	header := w.Header()
+	header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.maxAge.Seconds())))
+	header.Set("ETag", etag)
+	header.Set("Vary", "Accept-Encoding")
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Type", "application/jwk-set+json")
+	header.Set("Content-Length", fmt.Sprint(len(body)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(body)
+	}
+}
@@ -0,0 +1,151 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestKeyRing_JWKS(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	var ringKeys []Key
+	for _, m := range []SigningMethod{SigningMethodEdDSA, SigningMethodRS256, SigningMethodES384, SigningMethodHS256} {
+		ringKeys = append(ringKeys, Key{ID: string(m), SigningMethod: m, State: KeyVerifyOnly, PublicKey: keys[m].publicKey})
+	}
+	ring, err := NewKeyRing(ringKeys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks, err := ring.JWKS()
+	if err != nil {
+		t.Fatalf("JWKS() error = %v", err)
+	}
+	if len(jwks.Keys) != 3 {
+		t.Fatalf("JWKS() got %d keys, want 3 (HMAC excluded)", len(jwks.Keys))
+	}
+
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "sig" || jwk.Alg != jwk.Kid {
+			t.Errorf("JWK %s: use = %s, alg = %s", jwk.Kid, jwk.Use, jwk.Alg)
+		}
+		parser, err := newParser(SigningMethod(jwk.Kid), keys[SigningMethod(jwk.Kid)].publicKey, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		switch pk := parser.publicKey.(type) {
+		case ed25519.PublicKey:
+			if jwk.Kty != "OKP" || jwk.Crv != "Ed25519" || jwk.X != b64(pk) {
+				t.Errorf("OKP JWK = %+v", jwk)
+			}
+		case *rsa.PublicKey:
+			if jwk.Kty != "RSA" || jwk.N != b64(pk.N.Bytes()) || jwk.E != "AQAB" {
+				t.Errorf("RSA JWK = %+v", jwk)
+			}
+		case *ecdsa.PublicKey:
+			if jwk.Kty != "EC" || jwk.Crv != "P-384" || jwk.X != b64(pk.X.FillBytes(make([]byte, 48))) || jwk.Y != b64(pk.Y.FillBytes(make([]byte, 48))) {
+				t.Errorf("EC JWK = %+v", jwk)
+			}
+		}
+	}
+}
+
+func TestTokenGenerator_JWKS(t *testing.T) {
+	keys := newTestKeyPairs(t)
+
+	g, err := NewTokenGenerator(SigningMethodES256, keys[SigningMethodES256].privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, err := g.JWKS()
+	if err != nil || len(jwks.Keys) != 1 {
+		t.Fatalf("JWKS() = %v, %v", jwks, err)
+	}
+	if jwks.Keys[0].Kid != jwks.Keys[0].Thumbprint() {
+		t.Errorf("kid = %s, want thumbprint", jwks.Keys[0].Kid)
+	}
+
+	g, err = NewTokenGenerator(SigningMethodHS256, keys[SigningMethodHS256].privateKey, WithKeyID("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jwks, err := g.JWKS(); err != nil || len(jwks.Keys) != 0 {
+		t.Errorf("JWKS() = %v, %v, want empty set for HMAC", jwks, err)
+	}
+}
+
+func TestJWK_Thumbprint(t *testing.T) {
+	// RFC 7638 3.1
+	jwk := JWK{
+		Kty: "RSA",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+		Alg: "RS256",
+		Kid: "2011-04-29",
+	}
+	if got, want := jwk.Thumbprint(), "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; got != want {
+		t.Errorf("Thumbprint() = %s, want %s", got, want)
+	}
+}
+
+func TestJWKSHandler(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	ring, err := NewKeyRing(Key{ID: "k1", SigningMethod: SigningMethodEdDSA, State: KeyActive,
+		PrivateKey: keys[SigningMethodEdDSA].privateKey, PublicKey: keys[SigningMethodEdDSA].publicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(NewJWKSHandler(ring))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + JWKSPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/jwk-set+json" {
+		t.Errorf("Content-Type = %s", ct)
+	}
+	if cc := resp.Header.Get("Cache-Control"); cc != "public, max-age=300" {
+		t.Errorf("Cache-Control = %s", cc)
+	}
+	var jwks JWKSet
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil || len(jwks.Keys) != 1 || jwks.Keys[0].Kid != "k1" {
+		t.Errorf("body = %+v, %v", jwks, err)
+	}
+
+	// 命中 ETag 时返回 304
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+JWKSPath, nil)
+	req.Header.Set("If-None-Match", resp.Header.Get("ETag"))
+	resp2, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp2.Body.Close()
+	if resp2.StatusCode != http.StatusNotModified {
+		t.Errorf("status = %d, want 304", resp2.StatusCode)
+	}
+
+	resp3, err := http.Post(srv.URL+JWKSPath, "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp3.Body.Close()
+	if resp3.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want 405", resp3.StatusCode)
+	}
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
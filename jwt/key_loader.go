@@ -0,0 +1,418 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	jose "github.com/go-jose/go-jose/v3"
+)
+
+var (
+	ErrKeyNotFound = errors.New("key not found")
+	ErrEmptyKey    = errors.New("empty key")
+	// ErrUnsupportedKeyFormat 无法识别的密钥格式
+	ErrUnsupportedKeyFormat = errors.New("unsupported key format")
+	// ErrMalformedKey 识别出了格式，但内容无法解析
+	ErrMalformedKey = errors.New("malformed key")
+	// ErrEncryptedKey 加密的 PEM 私钥需先解密
+	ErrEncryptedKey = errors.New("encrypted keys are not supported")
+	// ErrKeyTypeMismatch 密钥类型与签名算法不符，如 ES256 使用了 RSA 密钥
+	ErrKeyTypeMismatch = errors.New("key type does not match signing method")
+)
+
+// hmacSecretBase64Prefix 以此为前缀的 HMAC 密钥按 base64 解码
+const hmacSecretBase64Prefix = "base64:"
+
+// KeyError describes why a key could not be read or parsed, it matches ErrInvalidPrivateKey or ErrInvalidPublicKey
+// for parse errors, and the precise cause (ErrKeyNotFound, ErrUnsupportedKeyFormat, ErrKeyTypeMismatch...) via errors.Is
+type KeyError struct {
+	// Source 密钥来源，如 "file /etc/jwt/key.pem"、"env JWT_KEY"，直接传入字节时为空
+	Source string
+	// Format 识别出的格式，如 "PKCS#1 PEM"，未识别时为空
+	Format string
+	Err    error
+
+	kind error // ErrInvalidPrivateKey 或 ErrInvalidPublicKey，读取错误时为 nil
+}
+
+func (e *KeyError) Error() string {
+	var b strings.Builder
+	if e.kind != nil {
+		b.WriteString(e.kind.Error())
+	} else {
+		b.WriteString("key")
+	}
+	var details []string
+	if e.Source != "" {
+		details = append(details, e.Source)
+	}
+	if e.Format != "" {
+		details = append(details, e.Format)
+	}
+	if len(details) > 0 {
+		b.WriteString(" (" + strings.Join(details, ", ") + ")")
+	}
+	b.WriteString(": " + e.Err.Error())
+	return b.String()
+}
+
+func (e *KeyError) Unwrap() []error {
+	if e.kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.kind, e.Err}
+}
+
+// withSource 为解析错误补充来源
+func withSource(err error, source string) error {
+	var ke *KeyError
+	if errors.As(err, &ke) && ke.Source == "" {
+		c := *ke
+		c.Source = source
+		return &c
+	}
+	return err
+}
+
+// ParsePrivateKey parse a private key, detecting PKCS#8, PKCS#1 (RSA) and SEC1 (EC) in PEM or DER form, and JWK JSON
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	key, format, err := parsePrivateKey(data)
+	if err != nil {
+		return nil, &KeyError{Format: format, Err: err, kind: ErrInvalidPrivateKey}
+	}
+	return key, nil
+}
+
+// ParsePublicKey parse a public key, detecting SPKI, PKCS#1 (RSA) and X.509 certificates in PEM or DER form,
+// and JWK JSON (public part of a private JWK is used)
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	key, format, err := parsePublicKey(data)
+	if err != nil {
+		return nil, &KeyError{Format: format, Err: err, kind: ErrInvalidPublicKey}
+	}
+	return key, nil
+}
+
+// ParseHMACSecret parse a HMAC secret: an "oct" JWK, "base64:" followed by the std or url base64 encoded secret,
+// otherwise the bytes are the secret itself. The constructors use HMAC secrets as raw bytes and never decode them,
+// call ParseHMACSecret explicitly for secrets in these encodings, e.g. the output of GenerateKey
+func ParseHMACSecret(data []byte) ([]byte, error) {
+	secret, format, err := parseHMACSecret(data)
+	if err != nil {
+		return nil, &KeyError{Format: format, Err: err, kind: ErrInvalidPrivateKey}
+	}
+	return secret, nil
+}
+
+func parsePrivateKey(data []byte) (crypto.Signer, string, error) {
+	text := bytes.TrimSpace(data)
+	if len(text) == 0 {
+		return nil, "", ErrEmptyKey
+	}
+	if isJSONObject(text) {
+		k, format, err := parseJWK(text, true)
+		if err != nil {
+			return nil, format, err
+		}
+		s, ok := k.(crypto.Signer)
+		if !ok {
+			return nil, format, fmt.Errorf("%w: %T", ErrUnsupportedKeyType, k)
+		}
+		return s, format, nil
+	}
+
+	// DER 不能去除首尾空白
+	der := data
+	var pemType string
+	if bytes.Contains(text, []byte("-----BEGIN")) {
+		block, err := decodePEM(text)
+		if err != nil {
+			return nil, "PEM", err
+		}
+		if block.Type == "ENCRYPTED PRIVATE KEY" || x509.IsEncryptedPEMBlock(block) { //nolint:staticcheck // 只用于识别
+			return nil, block.Type + " PEM", ErrEncryptedKey
+		}
+		der, pemType = block.Bytes, block.Type
+	}
+
+	switch pemType {
+	case "PRIVATE KEY":
+		return parsePKCS8(der, "PKCS#8 PEM")
+	case "RSA PRIVATE KEY":
+		k, err := x509.ParsePKCS1PrivateKey(der)
+		if err != nil {
+			return nil, "PKCS#1 PEM", fmt.Errorf("%w: %v", ErrMalformedKey, err)
+		}
+		return k, "PKCS#1 PEM", nil
+	case "EC PRIVATE KEY":
+		k, err := x509.ParseECPrivateKey(der)
+		if err != nil {
+			return nil, "SEC1 PEM", fmt.Errorf("%w: %v", ErrMalformedKey, err)
+		}
+		return k, "SEC1 PEM", nil
+	case "":
+	default:
+		return nil, pemType + " PEM", ErrUnsupportedKeyFormat
+	}
+
+	// DER：依次尝试 PKCS#8、PKCS#1、SEC1
+	if k, _, err := parsePKCS8(der, "PKCS#8 DER"); err == nil {
+		return k, "PKCS#8 DER", nil
+	}
+	if k, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return k, "PKCS#1 DER", nil
+	}
+	if k, err := x509.ParseECPrivateKey(der); err == nil {
+		return k, "SEC1 DER", nil
+	}
+	return nil, "", ErrUnsupportedKeyFormat
+}
+
+func parsePKCS8(der []byte, format string) (crypto.Signer, string, error) {
+	k, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, format, fmt.Errorf("%w: %v", ErrMalformedKey, err)
+	}
+	s, ok := k.(crypto.Signer)
+	if !ok {
+		return nil, format, fmt.Errorf("%w: %T", ErrUnsupportedKeyType, k)
+	}
+	return s, format, nil
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, string, error) {
+	text := bytes.TrimSpace(data)
+	if len(text) == 0 {
+		return nil, "", ErrEmptyKey
+	}
+	if isJSONObject(text) {
+		k, format, err := parseJWK(text, false)
+		if err != nil {
+			return nil, format, err
+		}
+		if s, ok := k.(crypto.Signer); ok {
+			return s.Public(), format, nil
+		}
+		return k, format, nil
+	}
+
+	// DER 不能去除首尾空白
+	der := data
+	var pemType string
+	if bytes.Contains(text, []byte("-----BEGIN")) {
+		block, err := decodePEM(text)
+		if err != nil {
+			return nil, "PEM", err
+		}
+		der, pemType = block.Bytes, block.Type
+	}
+
+	malformed := func(format string, err error) (crypto.PublicKey, string, error) {
+		return nil, format, fmt.Errorf("%w: %v", ErrMalformedKey, err)
+	}
+	switch pemType {
+	case "PUBLIC KEY":
+		k, err := x509.ParsePKIXPublicKey(der)
+		if err != nil {
+			return malformed("SPKI PEM", err)
+		}
+		return k, "SPKI PEM", nil
+	case "RSA PUBLIC KEY":
+		k, err := x509.ParsePKCS1PublicKey(der)
+		if err != nil {
+			return malformed("PKCS#1 PEM", err)
+		}
+		return k, "PKCS#1 PEM", nil
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return malformed("X.509 certificate PEM", err)
+		}
+		return cert.PublicKey, "X.509 certificate PEM", nil
+	case "PRIVATE KEY", "RSA PRIVATE KEY", "EC PRIVATE KEY", "ENCRYPTED PRIVATE KEY":
+		return nil, pemType + " PEM", fmt.Errorf("%w: got a private key where a public key is required", ErrUnsupportedKeyFormat)
+	case "":
+	default:
+		return nil, pemType + " PEM", ErrUnsupportedKeyFormat
+	}
+
+	// DER：依次尝试 SPKI、PKCS#1、证书
+	if k, err := x509.ParsePKIXPublicKey(der); err == nil {
+		return k, "SPKI DER", nil
+	}
+	if k, err := x509.ParsePKCS1PublicKey(der); err == nil {
+		return k, "PKCS#1 DER", nil
+	}
+	if cert, err := x509.ParseCertificate(der); err == nil {
+		return cert.PublicKey, "X.509 certificate DER", nil
+	}
+	return nil, "", ErrUnsupportedKeyFormat
+}
+
+func parseHMACSecret(data []byte) ([]byte, string, error) {
+	if isJSONObject(bytes.TrimSpace(data)) {
+		k, format, err := parseJWK(bytes.TrimSpace(data), true)
+		if err != nil {
+			return nil, format, err
+		}
+		secret, ok := k.([]byte)
+		if !ok {
+			return nil, format, fmt.Errorf("%w: HMAC requires an oct JWK", ErrKeyTypeMismatch)
+		}
+		return secret, format, nil
+	}
+	if s, ok := strings.CutPrefix(string(data), hmacSecretBase64Prefix); ok {
+		s = strings.TrimSpace(s)
+		secret, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			secret, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+		}
+		if err != nil {
+			return nil, "base64", fmt.Errorf("%w: %v", ErrMalformedKey, err)
+		}
+		if len(secret) == 0 {
+			return nil, "base64", ErrEmptyKey
+		}
+		return secret, "base64", nil
+	}
+	if len(data) == 0 {
+		return nil, "", ErrEmptyKey
+	}
+	return data, "", nil
+}
+
+// parseJWK 解析单个 JWK，private 为 true 时要求包含私钥，oct 密钥返回 []byte
+func parseJWK(data []byte, private bool) (interface{}, string, error) {
+	const format = "JWK"
+	var jwk jose.JSONWebKey
+	if err := json.Unmarshal(data, &jwk); err != nil {
+		return nil, format, fmt.Errorf("%w: %v", ErrMalformedKey, err)
+	}
+	// go-jose 的 Valid 不支持 oct 密钥
+	if _, oct := jwk.Key.([]byte); !oct && !jwk.Valid() {
+		return nil, format, fmt.Errorf("%w: invalid JWK", ErrMalformedKey)
+	}
+	if private && jwk.IsPublic() {
+		return nil, format, fmt.Errorf("%w: JWK has no private key", ErrKeyTypeMismatch)
+	}
+	return jwk.Key, format, nil
+}
+
+// decodePEM 返回第一个非 "EC PARAMETERS" 的 PEM 块（openssl ecparam -genkey 会输出该块）
+func decodePEM(data []byte) (*pem.Block, error) {
+	rest := data
+	for {
+		block, r := pem.Decode(rest)
+		if block == nil {
+			return nil, fmt.Errorf("%w: no PEM block found", ErrMalformedKey)
+		}
+		if block.Type != "EC PARAMETERS" {
+			return block, nil
+		}
+		rest = r
+	}
+}
+
+func isJSONObject(data []byte) bool {
+	return len(data) > 0 && data[0] == '{' && json.Valid(data)
+}
+
+// parseSigningKey 解析 method 使用的私钥，校验密钥类型与曲线，allowWeak 为 false 时校验密钥强度
+func parseSigningKey(method SigningMethod, data []byte, allowWeak bool) (interface{}, error) {
+	if method.isHMAC() {
+		if err := checkHMACSecret(method, data, allowWeak); err != nil {
+			return nil, &KeyError{Err: err, kind: ErrInvalidPrivateKey}
+		}
+		return data, nil
+	}
+	key, format, err := parsePrivateKey(data)
+	if err == nil {
+		err = checkKeyType(method, key.Public())
+	}
+	if err == nil && !allowWeak {
+		err = checkKeyStrength(method, key.Public())
+	}
+	if err != nil {
+		return nil, &KeyError{Format: format, Err: err, kind: ErrInvalidPrivateKey}
+	}
+	return key, nil
+}
+
+// parseVerificationKey 解析 method 使用的公钥，校验密钥类型与曲线，allowWeak 为 false 时校验密钥强度
+func parseVerificationKey(method SigningMethod, data []byte, allowWeak bool) (interface{}, error) {
+	if method.isHMAC() {
+		if err := checkHMACSecret(method, data, allowWeak); err != nil {
+			return nil, &KeyError{Err: err, kind: ErrInvalidPublicKey}
+		}
+		return data, nil
+	}
+	key, format, err := parsePublicKey(data)
+	if err == nil {
+		err = checkKeyType(method, key)
+	}
+	if err == nil && !allowWeak {
+		err = checkKeyStrength(method, key)
+	}
+	if err != nil {
+		return nil, &KeyError{Format: format, Err: err, kind: ErrInvalidPublicKey}
+	}
+	return key, nil
+}
+
+// checkHMACSecret 校验 HMAC 密钥，密钥按原始字节使用，"base64:" 与 JWK 需先经 ParseHMACSecret 解码
+func checkHMACSecret(method SigningMethod, secret []byte, allowWeak bool) error {
+	if len(secret) == 0 {
+		return ErrEmptyKey
+	}
+	if !allowWeak {
+		return checkKeyStrength(method, secret)
+	}
+	return nil
+}
+
+// checkKeyType 校验公钥类型与 method 是否匹配
+func checkKeyType(method SigningMethod, pub crypto.PublicKey) error {
+	switch method {
+	case SigningMethodEdDSA:
+		if _, ok := pub.(ed25519.PublicKey); ok {
+			return nil
+		}
+	case SigningMethodRS256, SigningMethodRS384, SigningMethodRS512:
+		if _, ok := pub.(*rsa.PublicKey); ok {
+			return nil
+		}
+	case SigningMethodES256, SigningMethodES384, SigningMethodES512:
+		if pk, ok := pub.(*ecdsa.PublicKey); ok {
+			if method.matchesCurve(pk.Curve) {
+				return nil
+			}
+			return fmt.Errorf("%w: %s requires %s, got %s", ErrCurveMismatch, method, method.curveName(), pk.Curve.Params().Name)
+		}
+	default:
+		return ErrUnknownSigningMethod
+	}
+	return fmt.Errorf("%w: %s requires %s key, got %s", ErrKeyTypeMismatch, method, method.keyTypeName(), keyTypeName(pub))
+}
+
+// keyTypeName 用于错误信息的密钥类型名
+func keyTypeName(pub crypto.PublicKey) string {
+	switch pk := pub.(type) {
+	case ed25519.PublicKey:
+		return "Ed25519"
+	case *rsa.PublicKey:
+		return "RSA"
+	case *ecdsa.PublicKey:
+		return "EC " + pk.Curve.Params().Name
+	}
+	return fmt.Sprintf("%T", pub)
+}
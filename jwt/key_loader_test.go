@@ -0,0 +1,401 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v3"
+)
+
+func TestParseKeyFormats(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	parsePriv := func(m SigningMethod) crypto.Signer {
+		k, err := ParsePrivateKey(keys[m].privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+	rsaKey := parsePriv(SigningMethodRS256).(*rsa.PrivateKey)
+	ecKey := parsePriv(SigningMethodES256).(*ecdsa.PrivateKey)
+	edKey := parsePriv(SigningMethodEdDSA)
+
+	pemBytes := func(typ string, der []byte) []byte {
+		return pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+	}
+	sec1, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecPKIX, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwkJSON := func(key interface{}) []byte {
+		b, err := json.Marshal(jose.JSONWebKey{Key: key})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "jwt"},
+		NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour)}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &ecKey.PublicKey, ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// openssl ecparam -genkey 的输出带有 EC PARAMETERS 块
+	ecParams := append(pemBytes("EC PARAMETERS", []byte{0x06, 0x08, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x03, 0x01, 0x07}), pemBytes("EC PRIVATE KEY", sec1)...)
+
+	privateTests := []struct {
+		name   string
+		method SigningMethod
+		data   []byte
+	}{
+		{name: "PKCS#8 PEM", method: SigningMethodEdDSA, data: keys[SigningMethodEdDSA].privateKey},
+		{name: "PKCS#1 PEM", method: SigningMethodRS256, data: pemBytes("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))},
+		{name: "SEC1 PEM", method: SigningMethodES256, data: pemBytes("EC PRIVATE KEY", sec1)},
+		{name: "SEC1 PEM with EC PARAMETERS", method: SigningMethodES256, data: ecParams},
+		{name: "PKCS#1 DER", method: SigningMethodRS256, data: x509.MarshalPKCS1PrivateKey(rsaKey)},
+		{name: "SEC1 DER", method: SigningMethodES256, data: sec1},
+		{name: "JWK EC", method: SigningMethodES256, data: jwkJSON(ecKey)},
+		{name: "JWK OKP", method: SigningMethodEdDSA, data: jwkJSON(edKey)},
+		{name: "HMAC raw", method: SigningMethodHS256, data: []byte("0123456789abcdef0123456789abcdef")},
+	}
+	for _, tt := range privateTests {
+		t.Run("private "+tt.name, func(t *testing.T) {
+			if _, err := newSigner(tt.method, tt.data, false); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	publicTests := []struct {
+		name   string
+		method SigningMethod
+		data   []byte
+	}{
+		{name: "SPKI PEM", method: SigningMethodES256, data: pemBytes("PUBLIC KEY", ecPKIX)},
+		{name: "SPKI DER", method: SigningMethodES256, data: ecPKIX},
+		{name: "PKCS#1 PEM", method: SigningMethodRS256, data: pemBytes("RSA PUBLIC KEY", x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey))},
+		{name: "certificate PEM", method: SigningMethodES256, data: pemBytes("CERTIFICATE", cert)},
+		{name: "certificate DER", method: SigningMethodES256, data: cert},
+		{name: "JWK public", method: SigningMethodES256, data: jwkJSON(&ecKey.PublicKey)},
+		{name: "JWK private", method: SigningMethodES256, data: jwkJSON(ecKey)},
+	}
+	for _, tt := range publicTests {
+		t.Run("public "+tt.name, func(t *testing.T) {
+			if _, err := newParser(tt.method, tt.data, false); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestParseKeyErrors(t *testing.T) {
+	keys := newTestKeyPairs(t)
+
+	tests := []struct {
+		name       string
+		parse      func() error
+		wantErr    error
+		wantFormat string
+	}{
+		{name: "empty", parse: func() error { _, err := newSigner(SigningMethodRS256, []byte(" \n"), false); return err }, wantErr: ErrEmptyKey},
+		{name: "garbage", parse: func() error { _, err := newSigner(SigningMethodRS256, []byte("not a key"), false); return err }, wantErr: ErrUnsupportedKeyFormat},
+		{name: "corrupt PEM body", parse: func() error {
+			_, err := newSigner(SigningMethodRS256, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: []byte{1, 2, 3}}), false)
+			return err
+		}, wantErr: ErrMalformedKey, wantFormat: "PKCS#1 PEM"},
+		{name: "encrypted", parse: func() error {
+			_, err := newSigner(SigningMethodRS256, pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: []byte{1}}), false)
+			return err
+		}, wantErr: ErrEncryptedKey, wantFormat: "ENCRYPTED PRIVATE KEY PEM"},
+		{name: "openssh", parse: func() error {
+			_, err := newSigner(SigningMethodEdDSA, pem.EncodeToMemory(&pem.Block{Type: "OPENSSH PRIVATE KEY", Bytes: []byte{1}}), false)
+			return err
+		}, wantErr: ErrUnsupportedKeyFormat, wantFormat: "OPENSSH PRIVATE KEY PEM"},
+		{name: "wrong key type", parse: func() error {
+			_, err := newSigner(SigningMethodES256, keys[SigningMethodRS256].privateKey, false)
+			return err
+		}, wantErr: ErrKeyTypeMismatch, wantFormat: "PKCS#8 PEM"},
+		{name: "private as public", parse: func() error {
+			_, err := newParser(SigningMethodEdDSA, keys[SigningMethodEdDSA].privateKey, false)
+			return err
+		}, wantErr: ErrUnsupportedKeyFormat, wantFormat: "PRIVATE KEY PEM"},
+		{name: "public JWK as private", parse: func() error {
+			_, err := ParsePrivateKey([]byte(`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`))
+			return err
+		}, wantErr: ErrKeyTypeMismatch, wantFormat: "JWK"},
+		{name: "bad base64 secret", parse: func() error { _, err := ParseHMACSecret([]byte("base64:!!!")); return err }, wantErr: ErrMalformedKey, wantFormat: "base64"},
+		{name: "public JWK as HMAC secret", parse: func() error {
+			_, err := ParseHMACSecret([]byte(`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`))
+			return err
+		}, wantErr: ErrKeyTypeMismatch, wantFormat: "JWK"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.parse()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if !errors.Is(err, ErrInvalidPrivateKey) && !errors.Is(err, ErrInvalidPublicKey) {
+				t.Errorf("error = %v, want ErrInvalidPrivateKey or ErrInvalidPublicKey", err)
+			}
+			var ke *KeyError
+			if !errors.As(err, &ke) || ke.Format != tt.wantFormat {
+				t.Errorf("KeyError = %+v, want format %q", ke, tt.wantFormat)
+			}
+		})
+	}
+}
+
+func TestParseHMACSecret(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "raw", data: secret},
+		{name: "base64", data: []byte("base64:" + base64.StdEncoding.EncodeToString(secret) + "\n")},
+		{name: "base64url", data: []byte("base64:" + base64.RawURLEncoding.EncodeToString(secret))},
+		{name: "JWK", data: []byte(`{"kty":"oct","k":"` + base64.RawURLEncoding.EncodeToString(secret) + `"}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHMACSecret(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, secret) {
+				t.Errorf("ParseHMACSecret() = %q, want %q", got, secret)
+			}
+		})
+	}
+
+	// 构造函数按原始字节使用 HMAC 密钥，不解码 "base64:" 前缀
+	raw := []byte("base64:" + base64.StdEncoding.EncodeToString(secret))
+	g, err := NewTokenGenerator(SigningMethodHS256, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenStr, err := g.Generate(TokenInfo{UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewTokenVerifier(SigningMethodHS256, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Verify(tokenStr); err != nil {
+		t.Errorf("Verify() with the raw secret error = %v", err)
+	}
+	decoded, err := NewTokenVerifier(SigningMethodHS256, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decoded.Verify(tokenStr); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify() with the decoded secret error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestReadKey(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(path, keys[SigningMethodEdDSA].privateKey, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ReadKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newSigner(SigningMethodEdDSA, data, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// 单行并转义换行的 PEM
+	t.Setenv("JWT_TEST_KEY", strings.ReplaceAll(string(keys[SigningMethodEdDSA].privateKey), "\n", `\n`))
+	if data, err = ReadKeyEnv("JWT_TEST_KEY"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newSigner(SigningMethodEdDSA, data, false); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys := fstest.MapFS{"keys/pub.pem": {Data: keys[SigningMethodEdDSA].publicKey}, "keys/empty.pem": {}}
+	if data, err = ReadKeyFS(fsys, "keys/pub.pem"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newParser(SigningMethodEdDSA, data, false); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		read       func() ([]byte, error)
+		wantErr    error
+		wantSource string
+	}{
+		{name: "missing file", read: func() ([]byte, error) { return ReadKeyFile(filepath.Join(dir, "missing.pem")) }, wantErr: ErrKeyNotFound, wantSource: "file " + filepath.Join(dir, "missing.pem")},
+		{name: "unset env", read: func() ([]byte, error) { return ReadKeyEnv("JWT_TEST_UNSET_KEY") }, wantErr: ErrKeyNotFound, wantSource: "env JWT_TEST_UNSET_KEY"},
+		{name: "empty fs file", read: func() ([]byte, error) { return ReadKeyFS(fsys, "keys/empty.pem") }, wantErr: ErrEmptyKey, wantSource: "fs keys/empty.pem"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.read()
+			var ke *KeyError
+			if !errors.Is(err, tt.wantErr) || !errors.As(err, &ke) || ke.Source != tt.wantSource {
+				t.Errorf("error = %v, want %v from %s", err, tt.wantErr, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestKeyRingFromFiles(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	dir := t.TempDir()
+	write := func(name string, data []byte) {
+		t.Helper()
+		// 先写临时文件再 rename，与 Kubernetes secret 的原子更新一致
+		tmp := filepath.Join(dir, "."+name)
+		if err := os.WriteFile(tmp, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.pem", keys[SigningMethodEdDSA].privateKey)
+	files := []KeyFile{{ID: "a", SigningMethod: SigningMethodEdDSA, State: KeyActive, PrivateKeyFile: filepath.Join(dir, "a.pem")}}
+
+	ring, err := NewKeyRingFromFiles(files...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewTokenGeneratorWithKeyRing(ring)
+	v := NewTokenVerifierWithKeyRing(ring)
+	first, err := g.Generate(TokenInfo{UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Verify(first); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var reloadErrs []error
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ring.WatchFiles(ctx, 5*time.Millisecond, func(err error) {
+		mu.Lock()
+		reloadErrs = append(reloadErrs, err)
+		mu.Unlock()
+	}, files...)
+
+	// 写入无效内容：保留原有密钥并报告一次错误
+	write("a.pem", []byte("garbage"))
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(reloadErrs) > 0
+	})
+	if _, err := v.Verify(first); err != nil {
+		t.Fatalf("Verify() after failed reload error = %v", err)
+	}
+	mu.Lock()
+	if len(reloadErrs) != 1 || !errors.Is(reloadErrs[0], ErrUnsupportedKeyFormat) || !strings.Contains(reloadErrs[0].Error(), "a.pem") {
+		t.Errorf("reload errors = %v", reloadErrs)
+	}
+	mu.Unlock()
+
+	// 轮换为新密钥
+	write("a.pem", newTestEd25519PEM(t))
+	waitFor(t, func() bool {
+		_, err := v.Verify(first)
+		return err != nil
+	})
+	second, err := g.Generate(TokenInfo{UserID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Verify(second); err != nil {
+		t.Fatal(err)
+	}
+
+	// HMAC 密钥文件按 ParseHMACSecret 解码，可直接使用 GenerateKey 的输出
+	secret, _, err := GenerateKey(SigningMethodHS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write("hmac.key", secret)
+	hmacRing, err := NewKeyRingFromFiles(KeyFile{ID: "h", SigningMethod: SigningMethodHS256, State: KeyActive, PrivateKeyFile: filepath.Join(dir, "hmac.key")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hmacToken, err := NewTokenGeneratorWithKeyRing(hmacRing).Generate(TokenInfo{UserID: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := ParseHMACSecret(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hv, err := NewTokenVerifier(SigningMethodHS256, decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hv.Verify(hmacToken); err != nil {
+		t.Errorf("Verify() HMAC key file token error = %v", err)
+	}
+
+	_, err = NewKeyRingFromFiles(KeyFile{ID: "b", SigningMethod: SigningMethodEdDSA, State: KeyActive, PrivateKeyFile: filepath.Join(dir, "b.pem")})
+	if !errors.Is(err, ErrKeyNotFound) || !strings.Contains(err.Error(), "key b") {
+		t.Errorf("NewKeyRingFromFiles() missing file error = %v", err)
+	}
+}
+
+// waitFor 轮询 cond 直到为 true，超时失败
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func newTestEd25519PEM(t *testing.T) []byte {
+	t.Helper()
+	_, k, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
@@ -0,0 +1,218 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
+// ReadKeyFile read key material from the file at path, see ParsePrivateKey/ParsePublicKey/ParseHMACSecret for the formats
+func ReadKeyFile(path string) ([]byte, error) {
+	return readKey("file "+path, func() ([]byte, error) { return os.ReadFile(path) })
+}
+
+// ReadKeyFS read key material from name in fsys, e.g. an embed.FS
+func ReadKeyFS(fsys fs.FS, name string) ([]byte, error) {
+	return readKey("fs "+name, func() ([]byte, error) { return fs.ReadFile(fsys, name) })
+}
+
+// ReadKeyEnv read key material from the environment variable name,
+// a PEM value written on one line with literal "\n" is unescaped
+func ReadKeyEnv(name string) ([]byte, error) {
+	return readKey("env "+name, func() ([]byte, error) {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fs.ErrNotExist
+		}
+		if strings.Contains(v, "-----BEGIN") && !strings.Contains(v, "\n") {
+			v = strings.ReplaceAll(v, `\n`, "\n")
+		}
+		return []byte(v), nil
+	})
+}
+
+func readKey(source string, read func() ([]byte, error)) ([]byte, error) {
+	data, err := read()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &KeyError{Source: source, Err: ErrKeyNotFound}
+	}
+	if err != nil {
+		return nil, &KeyError{Source: source, Err: err}
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, &KeyError{Source: source, Err: ErrEmptyKey}
+	}
+	return data, nil
+}
+
+// KeyFile a KeyRing key whose key material is read from files, see NewKeyRingFromFiles.
+// HMAC key files are decoded with ParseHMACSecret, so the output of GenerateKey can be used as is
+type KeyFile struct {
+	ID            string
+	SigningMethod SigningMethod
+	State         KeyState
+	// PrivateKeyFile 私钥文件，只有 KeyActive 需要
+	PrivateKeyFile string
+	// PublicKeyFile 公钥或证书文件，为空时从私钥推导
+	PublicKeyFile string
+	// FS 非空时从 FS 读取文件，否则从本地文件系统读取
+	FS fs.FS
+	// AllowWeakKey 跳过密钥强度校验，见 WithWeakSigningKey
+	AllowWeakKey bool
+}
+
+func (f KeyFile) read(name string) ([]byte, error) {
+	if f.FS != nil {
+		return ReadKeyFS(f.FS, name)
+	}
+	return ReadKeyFile(name)
+}
+
+// readKey 读取密钥文件，HMAC 密钥文件按 ParseHMACSecret 解码
+func (f KeyFile) readKey(name string) ([]byte, error) {
+	data, err := f.read(name)
+	if err != nil || !f.SigningMethod.isHMAC() {
+		return data, err
+	}
+	secret, err := ParseHMACSecret(data)
+	if err != nil {
+		return nil, withSource(err, f.source(name))
+	}
+	return secret, nil
+}
+
+func (f KeyFile) source(name string) string {
+	if f.FS != nil {
+		return "fs " + name
+	}
+	return "file " + name
+}
+
+// load 读取并校验密钥文件，返回的 Key 可直接用于 KeyRing
+func (f KeyFile) load() (Key, error) {
+	k := Key{ID: f.ID, SigningMethod: f.SigningMethod, State: f.State, AllowWeakKey: f.AllowWeakKey}
+	if f.State == KeyRetired {
+		return k, nil
+	}
+
+	var err error
+	if f.PrivateKeyFile != "" {
+		if k.PrivateKey, err = f.readKey(f.PrivateKeyFile); err != nil {
+			return Key{}, err
+		}
+		if _, err := parseSigningKey(f.SigningMethod, k.PrivateKey, f.AllowWeakKey); err != nil {
+			return Key{}, withSource(err, f.source(f.PrivateKeyFile))
+		}
+	}
+
+	switch {
+	case f.PublicKeyFile != "":
+		if k.PublicKey, err = f.readKey(f.PublicKeyFile); err != nil {
+			return Key{}, err
+		}
+		if _, err := parseVerificationKey(f.SigningMethod, k.PublicKey, f.AllowWeakKey); err != nil {
+			return Key{}, withSource(err, f.source(f.PublicKeyFile))
+		}
+	case k.PrivateKey != nil && f.SigningMethod.isHMAC():
+		k.PublicKey = k.PrivateKey
+	case k.PrivateKey != nil:
+		// 从私钥推导公钥（SPKI DER）
+		priv, _ := ParsePrivateKey(k.PrivateKey)
+		if k.PublicKey, err = x509.MarshalPKIXPublicKey(priv.Public()); err != nil {
+			return Key{}, err
+		}
+	}
+	return k, nil
+}
+
+// loadKeyFiles 读取全部密钥文件，同时返回内容的摘要用于检测变化
+func loadKeyFiles(files []KeyFile) ([]Key, [sha256.Size]byte, error) {
+	h := sha256.New()
+	keys := make([]Key, 0, len(files))
+	for _, f := range files {
+		k, err := f.load()
+		if err != nil {
+			return nil, [sha256.Size]byte{}, fmt.Errorf("key %s: %w", f.ID, err)
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00%d\x00", k.ID, len(k.PrivateKey), len(k.PublicKey))
+		h.Write(k.PrivateKey)
+		h.Write(k.PublicKey)
+		keys = append(keys, k)
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return keys, sum, nil
+}
+
+// NewKeyRingFromFiles new a key ring from key files, errors name the key id and the file
+func NewKeyRingFromFiles(files ...KeyFile) (*KeyRing, error) {
+	keys, _, err := loadKeyFiles(files)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyRing(keys...)
+}
+
+// WatchFiles poll files every interval until ctx is done and replace the keys of the ring when any of them changes,
+// e.g. after a Kubernetes secret update. A failed reload (missing or half written file, invalid key) keeps the
+// current keys and is passed to onError once per distinct failure, onError may be nil
+func (r *KeyRing) WatchFiles(ctx context.Context, interval time.Duration, onError func(error), files ...KeyFile) {
+	w := &keyFileWatcher{ring: r, files: files, onError: onError}
+	// 以调用时的文件内容为基准，之后的变化才会触发替换
+	if _, sum, err := loadKeyFiles(files); err == nil {
+		w.last = sum
+	}
+	go w.loop(ctx, interval)
+}
+
+type keyFileWatcher struct {
+	ring    *KeyRing
+	files   []KeyFile
+	onError func(error)
+
+	last    [sha256.Size]byte
+	lastErr string
+}
+
+func (w *keyFileWatcher) loop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.reload()
+		}
+	}
+}
+
+func (w *keyFileWatcher) reload() {
+	keys, sum, err := loadKeyFiles(w.files)
+	if err == nil {
+		if sum == w.last {
+			return
+		}
+		// 内容已变化，无论替换是否成功都不再重试，直到文件再次变化
+		w.last = sum
+		err = w.ring.Replace(keys...)
+	}
+	if err == nil {
+		w.lastErr = ""
+		return
+	}
+	if err.Error() != w.lastErr {
+		w.lastErr = err.Error()
+		if w.onError != nil {
+			w.onError(err)
+		}
+	}
+}
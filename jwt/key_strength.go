@@ -0,0 +1,65 @@
+package jwt
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+)
+
+const (
+	// minRSAKeyBits RSA 密钥的最小长度
+	minRSAKeyBits = 2048
+)
+
+var (
+	ErrWeakKey = errors.New("key is too weak")
+	// ErrHMACSecretTooShort HMAC 密钥短于哈希输出（HS256/HS384/HS512 分别为 32/48/64 字节）
+	ErrHMACSecretTooShort = fmt.Errorf("%w: HMAC secret is shorter than the hash output", ErrWeakKey)
+	// ErrRSAKeyTooSmall RSA 密钥小于 2048 位
+	ErrRSAKeyTooSmall = fmt.Errorf("%w: RSA key is smaller than %d bits", ErrWeakKey, minRSAKeyBits)
+	// ErrCurveMismatch EC 曲线与签名算法不符，如 ES384 使用了 P-256 密钥，不能通过 WithWeakSigningKey 放行
+	ErrCurveMismatch = fmt.Errorf("%w: EC curve does not match signing method", ErrKeyTypeMismatch)
+)
+
+// WithWeakSigningKey skip the minimum key strength check (HMAC secret length, RSA key size),
+// only for legacy systems that cannot rotate their keys yet
+func WithWeakSigningKey() Option {
+	return func(g *generator) {
+		g.allowWeakKey = true
+	}
+}
+
+// WithWeakVerificationKey skip the minimum key strength check of the verification key, see WithWeakSigningKey
+func WithWeakVerificationKey() VerifierOption {
+	return func(v *verifier) {
+		v.allowWeakKey = true
+	}
+}
+
+// checkKeyStrength 校验 HMAC 密钥长度与 RSA 密钥位数，key 为 HMAC 密钥或公钥
+func checkKeyStrength(method SigningMethod, key interface{}) error {
+	switch k := key.(type) {
+	case []byte:
+		if min := method.hmacSecretSize(); len(k) < min {
+			return fmt.Errorf("%w: %s requires at least %d bytes, got %d", ErrHMACSecretTooShort, method, min, len(k))
+		}
+	case *rsa.PublicKey:
+		if bits := k.N.BitLen(); bits < minRSAKeyBits {
+			return fmt.Errorf("%w: got %d bits", ErrRSAKeyTooSmall, bits)
+		}
+	}
+	return nil
+}
+
+// hmacSecretSize HMAC 密钥的最小长度，等于哈希输出长度
+func (m SigningMethod) hmacSecretSize() int {
+	switch m {
+	case SigningMethodHS256:
+		return 32
+	case SigningMethodHS384:
+		return 48
+	case SigningMethodHS512:
+		return 64
+	}
+	return 0
+}
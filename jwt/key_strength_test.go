@@ -0,0 +1,95 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+)
+
+func TestKeyStrength(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	rsa1024, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(rsa1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weakRSA := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	pubDer, err := x509.MarshalPKIXPublicKey(&rsa1024.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weakRSAPub := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDer})
+
+	tests := []struct {
+		name       string
+		method     SigningMethod
+		privateKey []byte
+		publicKey  []byte
+		wantErr    error
+		allowable  bool // WithWeakSigningKey/WithWeakVerificationKey 可以放行
+	}{
+		{name: "HS256 31 bytes", method: SigningMethodHS256, privateKey: bytes.Repeat([]byte{1}, 31), publicKey: bytes.Repeat([]byte{1}, 31), wantErr: ErrHMACSecretTooShort, allowable: true},
+		{name: "HS384 47 bytes", method: SigningMethodHS384, privateKey: bytes.Repeat([]byte{1}, 47), publicKey: bytes.Repeat([]byte{1}, 47), wantErr: ErrHMACSecretTooShort, allowable: true},
+		{name: "HS512 3 bytes", method: SigningMethodHS512, privateKey: []byte("abc"), publicKey: []byte("abc"), wantErr: ErrHMACSecretTooShort, allowable: true},
+		{name: "RSA 1024", method: SigningMethodRS256, privateKey: weakRSA, publicKey: weakRSAPub, wantErr: ErrRSAKeyTooSmall, allowable: true},
+		{name: "ES384 with P-256", method: SigningMethodES384, privateKey: keys[SigningMethodES256].privateKey, publicKey: keys[SigningMethodES256].publicKey, wantErr: ErrCurveMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTokenGenerator(tt.method, tt.privateKey)
+			if !errors.Is(err, tt.wantErr) || !errors.Is(err, ErrInvalidPrivateKey) {
+				t.Errorf("NewTokenGenerator() error = %v, want %v", err, tt.wantErr)
+			}
+			_, err = NewTokenVerifier(tt.method, tt.publicKey)
+			if !errors.Is(err, tt.wantErr) || !errors.Is(err, ErrInvalidPublicKey) {
+				t.Errorf("NewTokenVerifier() error = %v, want %v", err, tt.wantErr)
+			}
+			_, err = NewKeyRing(Key{ID: "a", SigningMethod: tt.method, State: KeyActive, PrivateKey: tt.privateKey, PublicKey: tt.publicKey})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewKeyRing() error = %v, want %v", err, tt.wantErr)
+			}
+
+			// 显式放行后可以签发与验证
+			g, err := NewTokenGenerator(tt.method, tt.privateKey, WithWeakSigningKey())
+			if !tt.allowable {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("NewTokenGenerator(WithWeakSigningKey) error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := NewTokenVerifier(tt.method, tt.publicKey, WithWeakVerificationKey())
+			if err != nil {
+				t.Fatal(err)
+			}
+			tokenStr, err := g.Generate(TokenInfo{UserID: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := v.Verify(tokenStr); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := NewKeyRing(Key{ID: "a", SigningMethod: tt.method, State: KeyActive, PrivateKey: tt.privateKey, PublicKey: tt.publicKey, AllowWeakKey: true}); err != nil {
+				t.Errorf("NewKeyRing(AllowWeakKey) error = %v", err)
+			}
+		})
+	}
+
+	t.Run("crypto.Signer", func(t *testing.T) {
+		if _, err := NewTokenGeneratorWithSigner(rsa1024); !errors.Is(err, ErrRSAKeyTooSmall) {
+			t.Errorf("NewTokenGeneratorWithSigner() error = %v, want %v", err, ErrRSAKeyTooSmall)
+		}
+		if _, err := NewTokenGeneratorWithSigner(rsa1024, WithWeakSigningKey()); err != nil {
+			t.Errorf("NewTokenGeneratorWithSigner(WithWeakSigningKey) error = %v", err)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+)
+
+// GenerateKey generate a new key for method, privateKey is a PKCS#8 PEM and publicKey a SPKI PEM,
+// both are accepted by NewTokenGenerator/NewTokenVerifier. For HMAC both are the same "base64:" encoded secret
+// of the hash output size, decode it with ParseHMACSecret before use. RSA keys are 2048/3072/4096 bits for RS256/RS384/RS512
+func GenerateKey(method SigningMethod) (privateKey, publicKey []byte, err error) {
+	var key crypto.Signer
+	switch method {
+	case SigningMethodEdDSA:
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	case SigningMethodRS256:
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	case SigningMethodRS384:
+		key, err = rsa.GenerateKey(rand.Reader, 3072)
+	case SigningMethodRS512:
+		key, err = rsa.GenerateKey(rand.Reader, 4096)
+	case SigningMethodES256:
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case SigningMethodES384:
+		key, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case SigningMethodES512:
+		key, err = ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	case SigningMethodHS256, SigningMethodHS384, SigningMethodHS512:
+		secret := make([]byte, method.hmacSecretSize())
+		if _, err := rand.Read(secret); err != nil {
+			return nil, nil, err
+		}
+		encoded := []byte(hmacSecretBase64Prefix + base64.StdEncoding.EncodeToString(secret))
+		return encoded, encoded, nil
+	default:
+		return nil, nil, ErrUnknownSigningMethod
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	pubDer, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDer}), nil
+}
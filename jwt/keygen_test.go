@@ -0,0 +1,51 @@
+package jwt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateKey(t *testing.T) {
+	methods := []SigningMethod{SigningMethodEdDSA, SigningMethodRS256, SigningMethodES256, SigningMethodES384, SigningMethodES512,
+		SigningMethodHS256, SigningMethodHS384, SigningMethodHS512}
+	for _, m := range methods {
+		t.Run(string(m), func(t *testing.T) {
+			priv, pub, err := GenerateKey(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.isHMAC() && !strings.HasPrefix(string(priv), hmacSecretBase64Prefix) {
+				t.Errorf("HMAC secret = %q, want base64: prefix", priv)
+			}
+
+			if m.isHMAC() {
+				// HMAC 密钥需显式解码
+				if priv, err = ParseHMACSecret(priv); err != nil {
+					t.Fatal(err)
+				}
+				pub = priv
+			}
+
+			// 生成的密钥满足强度校验
+			g, err := NewTokenGenerator(m, priv)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := NewTokenVerifier(m, pub)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tokenStr, err := g.Generate(TokenInfo{UserID: 3})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := v.Verify(tokenStr); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	if _, _, err := GenerateKey("none"); err != ErrUnknownSigningMethod {
+		t.Errorf("GenerateKey(none) error = %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package jwt
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+var (
+	ErrNoActiveKey        = errors.New("no active key")
+	ErrMultipleActiveKeys = errors.New("multiple active keys")
+	ErrDuplicateKeyID     = errors.New("duplicate key id")
+	ErrEmptyKeyID         = errors.New("empty key id")
+	// ErrUnknownKeyID token 头部的 kid 不存在或对应的密钥已退役
+	ErrUnknownKeyID = fmt.Errorf("%w: unknown key id", ErrInvalidToken)
+)
+
+// KeyState 密钥生命周期状态
+type KeyState int
+
+const (
+	// KeyActive 用于签发和验证，同一时间最多一把
+	KeyActive KeyState = iota
+	// KeyVerifyOnly 只用于验证已签发的 token（轮换前的预发布或轮换后的过渡期）
+	KeyVerifyOnly
+	// KeyRetired 不再签发也不再验证
+	KeyRetired
+)
+
+func (s KeyState) String() string {
+	switch s {
+	case KeyActive:
+		return "active"
+	case KeyVerifyOnly:
+		return "verify-only"
+	case KeyRetired:
+		return "retired"
+	}
+	return fmt.Sprintf("KeyState(%d)", int(s))
+}
+
+// Key 密钥环中的一把密钥
+type Key struct {
+	// ID 写入 token 头部的 kid
+	ID            string
+	SigningMethod SigningMethod
+	State         KeyState
+	// PrivateKey PEM 格式的私钥（HMAC 为密钥本身），只有 KeyActive 需要
+	PrivateKey []byte
+	// Signer 外部签名器（HSM、KMS 等），设置时代替 PrivateKey，其公钥类型须与 SigningMethod 一致，
+	// RSA 签名器按 SigningMethod 使用 RS256/RS384/RS512
+	Signer crypto.Signer
+	// AllowWeakKey 跳过密钥强度校验，见 WithWeakSigningKey
+	AllowWeakKey bool
+	// PublicKey PEM 格式的公钥（HMAC 为密钥本身），KeyActive 和 KeyVerifyOnly 需要
+	PublicKey []byte
+}
+
+// KeyRing 多密钥的密钥环，支持运行时整体替换，
+// 使用它创建的 TokenGenerator/TokenVerifier 无需重建即可生效
+type KeyRing struct {
+	set atomic.Pointer[keySet]
+}
+
+// keySet 密钥环的不可变快照
+type keySet struct {
+	active   *ringKey
+	verifier map[string]*ringKey // kid -> 可用于验证的密钥
+}
+
+type ringKey struct {
+	id     string
+	signer *Signer
+	parser *Parser
+}
+
+// NewKeyRing new a key ring
+func NewKeyRing(keys ...Key) (*KeyRing, error) {
+	r := &KeyRing{}
+	if err := r.Replace(keys...); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Replace 原子地替换密钥环中的全部密钥，校验失败时保留原有密钥
+func (r *KeyRing) Replace(keys ...Key) error {
+	set := &keySet{verifier: make(map[string]*ringKey)}
+	seen := make(map[string]bool)
+
+	for _, k := range keys {
+		if k.ID == "" {
+			return ErrEmptyKeyID
+		}
+		if seen[k.ID] {
+			return fmt.Errorf("%w: %s", ErrDuplicateKeyID, k.ID)
+		}
+		seen[k.ID] = true
+
+		if k.State == KeyRetired {
+			continue
+		}
+
+		rk := &ringKey{id: k.ID}
+		parser, err := newParser(k.SigningMethod, k.PublicKey, k.AllowWeakKey)
+		if err != nil {
+			return fmt.Errorf("key %s: %w", k.ID, err)
+		}
+		rk.parser = parser
+
+		if k.State == KeyActive {
+			if set.active != nil {
+				return ErrMultipleActiveKeys
+			}
+			signer, err := newKeySigner(k)
+			if err != nil {
+				return fmt.Errorf("key %s: %w", k.ID, err)
+			}
+			rk.signer = signer
+			set.active = rk
+		}
+		set.verifier[k.ID] = rk
+	}
+
+	r.set.Store(set)
+	return nil
+}
+
+// newKeySigner 优先使用 Key.Signer
+func newKeySigner(k Key) (*Signer, error) {
+	if k.Signer == nil {
+		return newSigner(k.SigningMethod, k.PrivateKey, k.AllowWeakKey)
+	}
+	// 哈希算法由 Key.SigningMethod 决定，Signer 只需公钥类型匹配
+	return newCryptoSigner(k.Signer, k.SigningMethod, k.AllowWeakKey)
+}
+
+// activeKey 返回当前用于签发的密钥
+func (r *KeyRing) activeKey() (*ringKey, error) {
+	set := r.set.Load()
+	if set.active == nil {
+		return nil, ErrNoActiveKey
+	}
+	return set.active, nil
+}
+
+// verificationKey 按 kid 查找可用于验证的密钥，
+// token 没有 kid 且只有一把可用密钥时使用该密钥
+func (r *KeyRing) verificationKey(kid string) (*ringKey, error) {
+	set := r.set.Load()
+	if kid == "" && len(set.verifier) == 1 {
+		for _, rk := range set.verifier {
+			return rk, nil
+		}
+	}
+	rk, ok := set.verifier[kid]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+	return rk, nil
+}
@@ -0,0 +1,135 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestKeyRing_Rotation(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	k1 := Key{ID: "k1", SigningMethod: SigningMethodEdDSA, State: KeyActive,
+		PrivateKey: keys[SigningMethodEdDSA].privateKey, PublicKey: keys[SigningMethodEdDSA].publicKey}
+	k2 := Key{ID: "k2", SigningMethod: SigningMethodES256, State: KeyVerifyOnly,
+		PrivateKey: keys[SigningMethodES256].privateKey, PublicKey: keys[SigningMethodES256].publicKey}
+
+	ring, err := NewKeyRing(k1, k2)
+	if err != nil {
+		t.Fatalf("NewKeyRing() error = %v", err)
+	}
+	g := NewTokenGeneratorWithKeyRing(ring)
+	v := NewTokenVerifierWithKeyRing(ring)
+
+	t1, err := g.Generate(TokenInfo{UserID: 1})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	assertKid(t, t1, "k1")
+
+	// 轮换：k2 开始签发，k1 只用于验证
+	k1.State, k2.State = KeyVerifyOnly, KeyActive
+	if err := ring.Replace(k1, k2); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	t2, err := g.Generate(TokenInfo{UserID: 2})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	assertKid(t, t2, "k2")
+
+	for _, tokenStr := range []string{t1, t2} {
+		if _, err := v.Verify(tokenStr); err != nil {
+			t.Errorf("Verify() error = %v", err)
+		}
+	}
+
+	// k1 退役后签发的旧 token 不再有效
+	k1.State = KeyRetired
+	if err := ring.Replace(k1, k2); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	if _, err := v.Verify(t1); !errors.Is(err, ErrUnknownKeyID) || !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify() error = %v, want %v", err, ErrUnknownKeyID)
+	}
+	if _, err := v.Verify(t2); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+}
+
+func TestKeyRing_Replace_Invalid(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	active := func(id string) Key {
+		return Key{ID: id, SigningMethod: SigningMethodHS256, State: KeyActive,
+			PrivateKey: keys[SigningMethodHS256].privateKey, PublicKey: keys[SigningMethodHS256].publicKey}
+	}
+
+	tests := []struct {
+		name    string
+		keys    []Key
+		wantErr error
+	}{
+		{name: "multiple active", keys: []Key{active("a"), active("b")}, wantErr: ErrMultipleActiveKeys},
+		{name: "duplicate id", keys: []Key{active("a"), active("a")}, wantErr: ErrDuplicateKeyID},
+		{name: "empty id", keys: []Key{active("")}, wantErr: ErrEmptyKeyID},
+		{name: "invalid public key", keys: []Key{{ID: "a", SigningMethod: SigningMethodRS256, State: KeyVerifyOnly, PublicKey: []byte("x")}}, wantErr: ErrInvalidPublicKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring, err := NewKeyRing(active("old"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ring.Replace(tt.keys...); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Replace() error = %v, want %v", err, tt.wantErr)
+			}
+			// 校验失败时保留原有密钥
+			if key, err := ring.activeKey(); err != nil || key.id != "old" {
+				t.Errorf("activeKey() = %v, %v", key, err)
+			}
+		})
+	}
+}
+
+func TestKeyRing_NoActiveKey(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	ring, err := NewKeyRing(Key{ID: "a", SigningMethod: SigningMethodEdDSA, State: KeyVerifyOnly,
+		PublicKey: keys[SigningMethodEdDSA].publicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTokenGeneratorWithKeyRing(ring).Generate(TokenInfo{}); err != ErrNoActiveKey {
+		t.Errorf("Generate() error = %v, want %v", err, ErrNoActiveKey)
+	}
+}
+
+func TestTokenVerifier_Verify_KeyRingAlgorithmMismatch(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	rsaPub := keys[SigningMethodRS256].publicKey
+	ring, err := NewKeyRing(Key{ID: "rsa", SigningMethod: SigningMethodRS256, State: KeyVerifyOnly, PublicKey: rsaPub})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 使用 RSA 密钥的 kid，但以公钥为 HMAC 密钥伪造签名
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, TokenClaims{TokenInfo: TokenInfo{UserID: 1}})
+	token.Header["kid"] = "rsa"
+	forged, err := token.SignedString(rsaPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTokenVerifierWithKeyRing(ring).Verify(forged); !errors.Is(err, ErrAlgorithmMismatch) {
+		t.Errorf("Verify() error = %v, want %v", err, ErrAlgorithmMismatch)
+	}
+}
+
+func assertKid(t *testing.T, tokenStr, want string) {
+	t.Helper()
+	token, _, err := jwt.NewParser().ParseUnverified(tokenStr, &TokenClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := token.Header["kid"]; kid != want {
+		t.Errorf("kid = %v, want %v", kid, want)
+	}
+}
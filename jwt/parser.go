@@ -2,8 +2,6 @@ package jwt
 
 import (
 	"errors"
-
-	"github.com/golang-jwt/jwt/v5"
 )
 
 var (
@@ -15,29 +13,13 @@ type Parser struct {
 	publicKey     interface{}
 }
 
-func newParser(signingMethod SigningMethod, publicKey []byte) (*Parser, error) {
-	var pk interface{}
-	var err error
-
-	switch signingMethod {
-	case SigningMethodEdDSA:
-		pk, err = jwt.ParseEdPublicKeyFromPEM(publicKey)
-
-	case SigningMethodRS256, SigningMethodRS384, SigningMethodRS512:
-		pk, err = jwt.ParseRSAPublicKeyFromPEM(publicKey)
-
-	case SigningMethodES256, SigningMethodES384, SigningMethodES512:
-		pk, err = jwt.ParseECPublicKeyFromPEM(publicKey)
-
-	case SigningMethodHS256, SigningMethodHS384, SigningMethodHS512:
-		pk = publicKey
-
-	default:
+func newParser(signingMethod SigningMethod, publicKey []byte, allowWeak bool) (*Parser, error) {
+	if signingMethod.jwtMethod() == nil {
 		return nil, ErrUnknownSigningMethod
 	}
-
+	pk, err := parseVerificationKey(signingMethod, publicKey, allowWeak)
 	if err != nil {
-		return nil, ErrInvalidPublicKey
+		return nil, err
 	}
 
 	return &Parser{
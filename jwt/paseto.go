@@ -0,0 +1,300 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidPasetoPurpose = errors.New("invalid paseto purpose")
+	// ErrUnsupportedPasetoOption JWT 专用的 option（加密、密钥环、远程 JWKS、introspection）不能用于 PASETO
+	ErrUnsupportedPasetoOption = errors.New("option not supported for paseto")
+)
+
+// PasetoPurpose PASETO version and purpose
+type PasetoPurpose string
+
+const (
+	// PasetoV4Public Ed25519 签名，密钥与 SigningMethodEdDSA 使用的 PEM 相同
+	PasetoV4Public PasetoPurpose = "v4.public"
+	// PasetoV4Local XChaCha20 + BLAKE2b 加密，密钥为 32 字节
+	PasetoV4Local PasetoPurpose = "v4.local"
+)
+
+// pasetoTimeClaims PASETO 的时间 claims 为 RFC 3339 字符串，JWT 为 NumericDate
+var pasetoTimeClaims = []string{"exp", "iat", "nbf"}
+
+// PasetoClaimsGenerator PASETO v4 token generator for custom claims T, see ClaimsGenerator
+type PasetoClaimsGenerator[T jwt.Claims] struct {
+	*generator
+	registeredIndex []int
+	purpose         PasetoPurpose
+	secretKey       paseto.V4AsymmetricSecretKey
+	symmetricKey    paseto.V4SymmetricKey
+}
+
+// NewPasetoClaimsGenerator new a PASETO v4 token generator for custom claims T,
+// key is the Ed25519 private key (see ParsePrivateKey) for PasetoV4Public or the 32 bytes key for PasetoV4Local.
+// WithExpires/WithIssuer/WithAudience/WithSubjectFunc/WithJTI apply as for JWT, WithKeyID is written to the footer
+func NewPasetoClaimsGenerator[T jwt.Claims](purpose PasetoPurpose, key []byte, opts ...Option) (*PasetoClaimsGenerator[T], error) {
+	g := newGenerator(nil, nil, opts)
+	if g.err != nil {
+		return nil, g.err
+	}
+	if g.encrypter != nil {
+		return nil, ErrUnsupportedPasetoOption
+	}
+	index, ok := registeredClaimsIndex(reflect.TypeOf((*T)(nil)).Elem())
+	if !ok {
+		return nil, ErrInvalidClaimsType
+	}
+
+	pg := &PasetoClaimsGenerator[T]{generator: g, registeredIndex: index, purpose: purpose}
+	switch purpose {
+	case PasetoV4Public:
+		pk, err := parseSigningKey(SigningMethodEdDSA, key, false)
+		if err != nil {
+			return nil, err
+		}
+		edKey, ok := pk.(ed25519.PrivateKey)
+		if !ok {
+			return nil, ErrInvalidPrivateKey
+		}
+		if pg.secretKey, err = paseto.NewV4AsymmetricSecretKeyFromEd25519(edKey); err != nil {
+			return nil, ErrInvalidPrivateKey
+		}
+	case PasetoV4Local:
+		var err error
+		if pg.symmetricKey, err = paseto.V4SymmetricKeyFromBytes(key); err != nil {
+			return nil, ErrInvalidPrivateKey
+		}
+	default:
+		return nil, ErrInvalidPasetoPurpose
+	}
+	return pg, nil
+}
+
+// Generate generate a PASETO token, exp/iat/nbf and the configured iss/aud/sub/jti are filled when not set in claims
+func (g *PasetoClaimsGenerator[T]) Generate(claims T) (string, error) {
+	rc := reflect.ValueOf(&claims).Elem().FieldByIndex(g.registeredIndex).Addr().Interface().(*jwt.RegisteredClaims)
+	if err := g.fillRegisteredClaims(rc, claims); err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return "", err
+	}
+	for _, name := range pasetoTimeClaims {
+		var d *jwt.NumericDate
+		if raw, ok := m[name]; ok {
+			if err := json.Unmarshal(raw, &d); err != nil {
+				return "", err
+			}
+		}
+		if d != nil {
+			m[name], _ = json.Marshal(d.UTC().Format(time.RFC3339))
+		}
+	}
+	payload, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+
+	var footer []byte
+	if g.kid != "" {
+		footer, _ = json.Marshal(map[string]string{"kid": g.kid})
+	}
+	token, err := paseto.NewTokenFromClaimsJSON(payload, footer)
+	if err != nil {
+		return "", err
+	}
+
+	if g.purpose == PasetoV4Public {
+		return token.V4Sign(g.secretKey, nil), nil
+	}
+	return token.V4Encrypt(g.symmetricKey, nil), nil
+}
+
+// PasetoTokenGenerator PASETO v4 token generator for TokenInfo
+type PasetoTokenGenerator struct {
+	claims *PasetoClaimsGenerator[TokenClaims]
+}
+
+// NewPasetoTokenGenerator new a PASETO v4 token generator, see NewPasetoClaimsGenerator
+func NewPasetoTokenGenerator(purpose PasetoPurpose, key []byte, opts ...Option) (*PasetoTokenGenerator, error) {
+	g, err := NewPasetoClaimsGenerator[TokenClaims](purpose, key, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &PasetoTokenGenerator{claims: g}, nil
+}
+
+// Generate generate token
+func (g *PasetoTokenGenerator) Generate(info TokenInfo) (string, error) {
+	return g.claims.Generate(TokenClaims{TokenInfo: info})
+}
+
+// PasetoClaimsVerifier PASETO v4 token verifier for custom claims T, see ClaimsVerifier
+type PasetoClaimsVerifier[T jwt.Claims] struct {
+	*verifier
+	purpose      PasetoPurpose
+	publicKey    paseto.V4AsymmetricPublicKey
+	symmetricKey paseto.V4SymmetricKey
+}
+
+// NewPasetoClaimsVerifier new a PASETO v4 token verifier for custom claims T,
+// key is the Ed25519 public key (see ParsePublicKey) for PasetoV4Public or the 32 bytes key for PasetoV4Local
+func NewPasetoClaimsVerifier[T jwt.Claims](purpose PasetoPurpose, key []byte, opts ...VerifierOption) (*PasetoClaimsVerifier[T], error) {
+	v := newVerifier(nil, nil, nil, opts)
+	if v.err != nil {
+		return nil, v.err
+	}
+	if v.decrypter != nil || v.introspection != nil {
+		return nil, ErrUnsupportedPasetoOption
+	}
+
+	pv := &PasetoClaimsVerifier[T]{verifier: v, purpose: purpose}
+	switch purpose {
+	case PasetoV4Public:
+		pk, err := parseVerificationKey(SigningMethodEdDSA, key, false)
+		if err != nil {
+			return nil, err
+		}
+		edKey, ok := pk.(ed25519.PublicKey)
+		if !ok {
+			return nil, ErrInvalidPublicKey
+		}
+		if pv.publicKey, err = paseto.NewV4AsymmetricPublicKeyFromEd25519(edKey); err != nil {
+			return nil, ErrInvalidPublicKey
+		}
+	case PasetoV4Local:
+		var err error
+		if pv.symmetricKey, err = paseto.V4SymmetricKeyFromBytes(key); err != nil {
+			return nil, ErrInvalidPublicKey
+		}
+	default:
+		return nil, ErrInvalidPasetoPurpose
+	}
+	return pv, nil
+}
+
+// Verify verify token and return its claims
+func (v *PasetoClaimsVerifier[T]) Verify(tokenStr string) (*T, error) {
+	if !strings.HasPrefix(tokenStr, string(v.purpose)+".") {
+		if strings.HasPrefix(tokenStr, "v") && strings.Count(tokenStr, ".") >= 2 {
+			// 其他版本或用途的 PASETO
+			return nil, newVerificationError(ReasonAlgorithmMismatch, nil)
+		}
+		return nil, newVerificationError(ReasonMalformed, nil)
+	}
+
+	// 时间 claims 由 checkTimes 按 leeway 校验
+	parser := paseto.NewParserWithoutExpiryCheck()
+	var (
+		token *paseto.Token
+		err   error
+	)
+	if v.purpose == PasetoV4Public {
+		token, err = parser.ParseV4Public(v.publicKey, tokenStr, nil)
+		if err != nil {
+			return nil, newVerificationError(ReasonSignatureInvalid, err)
+		}
+	} else {
+		token, err = parser.ParseV4Local(v.symmetricKey, tokenStr, nil)
+		if err != nil {
+			return nil, newVerificationError(ReasonDecryptionFailed, err)
+		}
+	}
+
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(token.ClaimsJSON(), &m); err != nil {
+		return nil, newVerificationError(ReasonMalformed, err)
+	}
+	for _, name := range pasetoTimeClaims {
+		raw, ok := m[name]
+		if !ok {
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, newVerificationError(ReasonMalformed, err)
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, newVerificationError(ReasonMalformed, err)
+		}
+		m[name], _ = json.Marshal(jwt.NewNumericDate(t))
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, newVerificationError(ReasonMalformed, err)
+	}
+
+	var claims T
+	ptr, ok := any(&claims).(jwt.Claims)
+	if !ok {
+		return nil, ErrInvalidClaimsType
+	}
+	if err := json.Unmarshal(data, ptr); err != nil {
+		return nil, newVerificationError(ReasonMalformed, err)
+	}
+	if err := v.checkTimes(ptr); err != nil {
+		return nil, err
+	}
+	if err := v.checkClaims(ptr); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+// checkTimes 按 leeway 校验 exp/nbf，设置了 max age 时拒绝 iat 在未来的 token
+func (v *verifier) checkTimes(claims jwt.Claims) error {
+	now := v.clock.Now()
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil && !now.Before(exp.Add(v.leeway)) {
+		return newVerificationError(ReasonExpired, nil)
+	}
+	if nbf, err := claims.GetNotBefore(); err == nil && nbf != nil && now.Add(v.leeway).Before(nbf.Time) {
+		return newVerificationError(ReasonNotValidYet, nil)
+	}
+	if v.maxAge > 0 {
+		if iat, err := claims.GetIssuedAt(); err == nil && iat != nil && now.Add(v.leeway).Before(iat.Time) {
+			return newVerificationError(ReasonNotValidYet, nil)
+		}
+	}
+	return nil
+}
+
+// PasetoTokenVerifier PASETO v4 token verifier for TokenInfo
+type PasetoTokenVerifier struct {
+	claims *PasetoClaimsVerifier[TokenClaims]
+}
+
+// NewPasetoTokenVerifier new a PASETO v4 token verifier, see NewPasetoClaimsVerifier
+func NewPasetoTokenVerifier(purpose PasetoPurpose, key []byte, opts ...VerifierOption) (*PasetoTokenVerifier, error) {
+	v, err := NewPasetoClaimsVerifier[TokenClaims](purpose, key, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &PasetoTokenVerifier{claims: v}, nil
+}
+
+// Verify verify token
+func (v *PasetoTokenVerifier) Verify(tokenStr string) (*TokenInfo, error) {
+	c, err := v.claims.Verify(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	return &c.TokenInfo, nil
+}
@@ -0,0 +1,193 @@
+package jwt
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestPaseto(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	secret := bytes.Repeat([]byte{0x24}, 32)
+
+	tests := []struct {
+		name       string
+		purpose    PasetoPurpose
+		signKey    []byte
+		verifyKey  []byte
+		wantPrefix string
+	}{
+		{name: "v4.public", purpose: PasetoV4Public, signKey: keys[SigningMethodEdDSA].privateKey, verifyKey: keys[SigningMethodEdDSA].publicKey, wantPrefix: "v4.public."},
+		{name: "v4.local", purpose: PasetoV4Local, signKey: secret, verifyKey: secret, wantPrefix: "v4.local."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewPasetoTokenGenerator(tt.purpose, tt.signKey, WithExpires(time.Hour), WithIssuer("auth"), WithKeyID("k1"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := NewPasetoTokenVerifier(tt.purpose, tt.verifyKey, WithExpectedIssuer("auth"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tokenStr, err := g.Generate(TokenInfo{UserID: 7, Scopes: []string{"read"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(tokenStr, tt.wantPrefix) {
+				t.Fatalf("token = %s, want prefix %s", tokenStr, tt.wantPrefix)
+			}
+			info, err := v.Verify(tokenStr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.UserID != 7 || len(info.Scopes) != 1 {
+				t.Errorf("Verify() = %+v", info)
+			}
+
+			// 篡改
+			tampered := tokenStr[:len(tt.wantPrefix)+4] + "AAAA" + tokenStr[len(tt.wantPrefix)+8:]
+			if _, err := v.Verify(tampered); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Verify(tampered) error = %v", err)
+			}
+
+			// issuer 不匹配
+			other, err := NewPasetoTokenVerifier(tt.purpose, tt.verifyKey, WithExpectedIssuer("other"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := other.Verify(tokenStr); ReasonOf(err) != ReasonInvalidIssuer {
+				t.Errorf("Verify() with other issuer reason = %q", ReasonOf(err))
+			}
+		})
+	}
+}
+
+func TestPaseto_Claims(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	g, err := NewPasetoClaimsGenerator[testTenantClaims](PasetoV4Public, keys[SigningMethodEdDSA].privateKey, WithExpires(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewPasetoClaimsVerifier[testTenantClaims](PasetoV4Public, keys[SigningMethodEdDSA].publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenStr, err := g.Generate(testTenantClaims{TenantID: "acme", RegisteredClaims: jwt.RegisteredClaims{Subject: "u1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := v.Verify(tokenStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.TenantID != "acme" || got.Subject != "u1" {
+		t.Errorf("Verify() = %+v", got)
+	}
+	if got.ExpiresAt == nil || time.Until(got.ExpiresAt.Time) <= 59*time.Minute {
+		t.Errorf("ExpiresAt = %v", got.ExpiresAt)
+	}
+}
+
+func TestPaseto_Rejects(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	secret := bytes.Repeat([]byte{0x24}, 32)
+
+	public, err := NewPasetoTokenVerifier(PasetoV4Public, keys[SigningMethodEdDSA].publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, err := NewPasetoTokenVerifier(PasetoV4Local, secret, WithLeeway(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	localGen, err := NewPasetoTokenGenerator(PasetoV4Local, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	localToken, err := localGen.Generate(TokenInfo{UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiredGen, err := NewPasetoTokenGenerator(PasetoV4Local, secret, WithExpires(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiredToken, err := expiredGen.Generate(TokenInfo{UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherGen, err := NewPasetoTokenGenerator(PasetoV4Local, bytes.Repeat([]byte{0x01}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherToken, err := otherGen.Generate(TokenInfo{UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtGen, err := NewTokenGenerator(SigningMethodEdDSA, keys[SigningMethodEdDSA].privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtToken, err := jwtGen.Generate(TokenInfo{UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		v      *PasetoTokenVerifier
+		token  string
+		reason Reason
+	}{
+		{name: "wrong purpose", v: public, token: localToken, reason: ReasonAlgorithmMismatch},
+		{name: "wrong version", v: local, token: "v2" + strings.TrimPrefix(localToken, "v4"), reason: ReasonAlgorithmMismatch},
+		{name: "jwt", v: public, token: jwtToken, reason: ReasonMalformed},
+		{name: "wrong key", v: local, token: otherToken, reason: ReasonDecryptionFailed},
+		{name: "expired", v: local, token: expiredToken, reason: ReasonExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.v.Verify(tt.token)
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if got := ReasonOf(err); got != tt.reason {
+				t.Errorf("ReasonOf() = %q, want %q", got, tt.reason)
+			}
+		})
+	}
+}
+
+func TestPaseto_InvalidConfig(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	secret := bytes.Repeat([]byte{0x24}, 32)
+
+	if _, err := NewPasetoTokenGenerator(PasetoV4Public, keys[SigningMethodES256].privateKey); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Errorf("NewPasetoTokenGenerator(ES256 key) error = %v", err)
+	}
+	if _, err := NewPasetoTokenGenerator(PasetoV4Local, secret[:16]); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Errorf("NewPasetoTokenGenerator(short key) error = %v", err)
+	}
+	if _, err := NewPasetoTokenGenerator("v3.local", secret); !errors.Is(err, ErrInvalidPasetoPurpose) {
+		t.Errorf("NewPasetoTokenGenerator(v3.local) error = %v", err)
+	}
+	if _, err := NewPasetoTokenGenerator(PasetoV4Local, secret, WithEncryption(KeyAlgorithmDir, secret)); !errors.Is(err, ErrUnsupportedPasetoOption) {
+		t.Errorf("NewPasetoTokenGenerator(WithEncryption) error = %v", err)
+	}
+	if _, err := NewPasetoTokenVerifier(PasetoV4Local, secret, WithDecryption(KeyAlgorithmDir, secret)); !errors.Is(err, ErrUnsupportedPasetoOption) {
+		t.Errorf("NewPasetoTokenVerifier(WithDecryption) error = %v", err)
+	}
+	// introspection 由 NewIntrospectionClaimsVerifier 配置，这里直接设置 verifier 模拟
+	withIntrospection := func(v *verifier) { v.introspection = &introspectionClient{} }
+	if _, err := NewPasetoTokenVerifier(PasetoV4Local, secret, withIntrospection); !errors.Is(err, ErrUnsupportedPasetoOption) {
+		t.Errorf("NewPasetoTokenVerifier(introspection) error = %v", err)
+	}
+}
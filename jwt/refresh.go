@@ -0,0 +1,153 @@
+package jwt
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrRefreshTokenInvalid refresh token 格式错误、已过期或所在的 token 族已被撤销
+	ErrRefreshTokenInvalid = fmt.Errorf("%w: invalid refresh token", ErrInvalidToken)
+	// ErrRefreshTokenReused 使用了已轮换过的 refresh token，整个 token 族已被撤销
+	ErrRefreshTokenReused = fmt.Errorf("%w: refresh token reused", ErrInvalidToken)
+)
+
+const (
+	defaultRefreshTTL = time.Hour * 24 * 7
+)
+
+// RefreshOption is a refresh manager option
+type RefreshOption func(*refreshConfig)
+
+type refreshConfig struct {
+	ttl time.Duration
+}
+
+// WithRefreshTTL set the lifetime of refresh tokens, each rotation extends the family by ttl
+func WithRefreshTTL(ttl time.Duration) RefreshOption {
+	return func(c *refreshConfig) {
+		c.ttl = ttl
+	}
+}
+
+// TokenPair access token and refresh token
+type TokenPair struct {
+	AccessToken      string
+	RefreshToken     string
+	FamilyID         string
+	RefreshExpiresAt time.Time
+}
+
+// RefreshManager issues access/refresh token pairs for claims T and rotates refresh tokens.
+// refresh token 为不透明字符串，属于一个 token 族；每次 Refresh 都会轮换 refresh token，
+// 旧的 refresh token 再次出现时视为泄露，撤销整个 token 族
+type RefreshManager[T jwt.Claims] struct {
+	access *ClaimsGenerator[T]
+	store  RefreshStore
+	ttl    time.Duration
+}
+
+// NewRefreshManager new a refresh manager, access tokens are generated by access,
+// which should be configured with a short expiry, e.g. WithExpires(15*time.Minute)
+func NewRefreshManager[T jwt.Claims](access *ClaimsGenerator[T], store RefreshStore, opts ...RefreshOption) *RefreshManager[T] {
+	c := &refreshConfig{ttl: defaultRefreshTTL}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return &RefreshManager[T]{
+		access: access,
+		store:  store,
+		ttl:    c.ttl,
+	}
+}
+
+// IssuePair issue an access token for claims and a refresh token of a new token family
+func (m *RefreshManager[T]) IssuePair(ctx context.Context, claims T) (*TokenPair, error) {
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	familyID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	family := RefreshFamily{Current: refreshDigest(secret), Claims: data}
+	if err := m.store.Create(ctx, familyID, family, m.ttl); err != nil {
+		return nil, err
+	}
+	return m.pair(claims, familyID, secret)
+}
+
+// Refresh rotate refreshToken and issue a new pair, the access token carries the claims given to IssuePair
+// with exp/iat/nbf/jti regenerated. 重复使用已轮换的 refresh token 返回 ErrRefreshTokenReused 并撤销整个 token 族
+func (m *RefreshManager[T]) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	familyID, secret, ok := parseRefreshToken(refreshToken)
+	if !ok {
+		return nil, ErrRefreshTokenInvalid
+	}
+	next, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	family, err := m.store.Rotate(ctx, familyID, refreshDigest(secret), refreshDigest(next), m.ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims T
+	if err := json.Unmarshal(family.Claims, &claims); err != nil {
+		return nil, err
+	}
+	rc := reflect.ValueOf(&claims).Elem().FieldByIndex(m.access.registeredIndex).Addr().Interface().(*jwt.RegisteredClaims)
+	rc.ExpiresAt, rc.IssuedAt, rc.NotBefore, rc.ID = nil, nil, nil, ""
+
+	return m.pair(claims, familyID, next)
+}
+
+// Revoke revoke the token family of refreshToken, e.g. on logout
+func (m *RefreshManager[T]) Revoke(ctx context.Context, refreshToken string) error {
+	familyID, _, ok := parseRefreshToken(refreshToken)
+	if !ok {
+		return ErrRefreshTokenInvalid
+	}
+	return m.store.Revoke(ctx, familyID)
+}
+
+func (m *RefreshManager[T]) pair(claims T, familyID, secret string) (*TokenPair, error) {
+	accessToken, err := m.access.Generate(claims)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     familyID + "." + secret,
+		FamilyID:         familyID,
+		RefreshExpiresAt: m.access.clock.Now().Add(m.ttl),
+	}, nil
+}
+
+// parseRefreshToken refresh token 格式为 familyID.secret
+func parseRefreshToken(refreshToken string) (familyID, secret string, ok bool) {
+	familyID, secret, ok = strings.Cut(refreshToken, ".")
+	return familyID, secret, ok && familyID != "" && secret != ""
+}
+
+// refreshDigest 存储中只保存 refresh token 的摘要
+func refreshDigest(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,123 @@
+package jwt
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RefreshFamily state of a refresh token family
+type RefreshFamily struct {
+	Current string // 当前有效 refresh token 的摘要
+	Claims  []byte // 签发 access token 使用的 claims（JSON）
+}
+
+// RefreshStore stores refresh token families, implementations must be safe for concurrent use
+// and Rotate must be atomic
+type RefreshStore interface {
+	// Create 创建 token 族，ttl 后过期
+	Create(ctx context.Context, familyID string, family RefreshFamily, ttl time.Duration) error
+	// Rotate 族的当前摘要等于 current 时替换为 next 并续期 ttl，返回族状态；
+	// 族不存在时返回 ErrRefreshTokenInvalid，摘要不一致时撤销整个族并返回 ErrRefreshTokenReused
+	Rotate(ctx context.Context, familyID, current, next string, ttl time.Duration) (*RefreshFamily, error)
+	// Revoke 撤销整个 token 族
+	Revoke(ctx context.Context, familyID string) error
+}
+
+const memorySweepInterval = time.Minute
+
+// MemoryStoreOption is an in-memory store option, see NewMemoryRefreshStore and NewMemoryRevocationStore
+type MemoryStoreOption func(*memoryStoreOptions)
+
+type memoryStoreOptions struct {
+	clock Clock
+}
+
+// WithStoreClock set the clock used to expire entries of an in-memory store, default is the system clock.
+// Use the same clock as the generator and verifier, e.g. a jwttest.FakeClock in tests
+func WithStoreClock(clock Clock) MemoryStoreOption {
+	return func(o *memoryStoreOptions) {
+		if clock != nil {
+			o.clock = clock
+		}
+	}
+}
+
+func newMemoryStoreOptions(opts []MemoryStoreOption) memoryStoreOptions {
+	o := memoryStoreOptions{clock: systemClock{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+type memoryFamily struct {
+	RefreshFamily
+	expiresAt time.Time
+}
+
+// memoryRefreshStore 进程内的 RefreshStore，适用于单实例部署和测试
+type memoryRefreshStore struct {
+	mu        sync.Mutex
+	families  map[string]*memoryFamily
+	clock     Clock
+	lastSweep time.Time
+}
+
+// NewMemoryRefreshStore new an in-memory refresh store
+func NewMemoryRefreshStore(opts ...MemoryStoreOption) RefreshStore {
+	o := newMemoryStoreOptions(opts)
+	return &memoryRefreshStore{
+		families:  make(map[string]*memoryFamily),
+		clock:     o.clock,
+		lastSweep: o.clock.Now(),
+	}
+}
+
+func (s *memoryRefreshStore) Create(_ context.Context, familyID string, family RefreshFamily, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	if now.Sub(s.lastSweep) >= memorySweepInterval {
+		// 定期清理过期的族，避免无限增长
+		for id, f := range s.families {
+			if !now.Before(f.expiresAt) {
+				delete(s.families, id)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	s.families[familyID] = &memoryFamily{RefreshFamily: family, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+func (s *memoryRefreshStore) Rotate(_ context.Context, familyID, current, next string, ttl time.Duration) (*RefreshFamily, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	f, ok := s.families[familyID]
+	if !ok || !now.Before(f.expiresAt) {
+		delete(s.families, familyID)
+		return nil, ErrRefreshTokenInvalid
+	}
+	if f.Current != current {
+		delete(s.families, familyID)
+		return nil, ErrRefreshTokenReused
+	}
+
+	f.Current = next
+	f.expiresAt = now.Add(ttl)
+	family := f.RefreshFamily
+	return &family, nil
+}
+
+func (s *memoryRefreshStore) Revoke(_ context.Context, familyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.families, familyID)
+	return nil
+}
@@ -0,0 +1,94 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// rotateScript 原子地校验并轮换当前摘要，摘要不一致时删除整个族
+// KEYS[1] 族的 key；ARGV[1] 当前摘要，ARGV[2] 新摘要，ARGV[3] ttl（毫秒）
+// 返回 0 表示族不存在，1 表示重复使用，否则返回 claims
+const rotateScript = `
+local current = redis.call("HGET", KEYS[1], "current")
+if not current then
+	return 0
+end
+if current ~= ARGV[1] then
+	redis.call("DEL", KEYS[1])
+	return 1
+end
+redis.call("HSET", KEYS[1], "current", ARGV[2])
+redis.call("PEXPIRE", KEYS[1], ARGV[3])
+return redis.call("HGET", KEYS[1], "claims")
+`
+
+const defaultRefreshKeyPrefix = "jwt:refresh"
+
+// RedisRefreshStoreOption is a redis refresh store option
+type RedisRefreshStoreOption func(*redisRefreshStore)
+
+// WithRefreshKeyPrefix set the key prefix of refresh token families, default is "jwt:refresh"
+func WithRefreshKeyPrefix(keyPrefix string) RedisRefreshStoreOption {
+	return func(s *redisRefreshStore) {
+		s.keyPrefix = keyPrefix
+	}
+}
+
+// redisRefreshStore 基于 Redis hash 的 RefreshStore，每个族一个 key，字段为 current 与 claims
+type redisRefreshStore struct {
+	rd        *redis.Client
+	keyPrefix string
+}
+
+// NewRedisRefreshStore new a refresh store backed by redis
+func NewRedisRefreshStore(rd *redis.Client, opts ...RedisRefreshStoreOption) RefreshStore {
+	s := &redisRefreshStore{
+		rd:        rd,
+		keyPrefix: defaultRefreshKeyPrefix,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *redisRefreshStore) key(familyID string) string {
+	return fmt.Sprintf("%s:%s", s.keyPrefix, familyID)
+}
+
+func (s *redisRefreshStore) Create(ctx context.Context, familyID string, family RefreshFamily, ttl time.Duration) error {
+	key := s.key(familyID)
+	_, err := s.rd.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "current", family.Current, "claims", family.Claims)
+		pipe.PExpire(ctx, key, ttl)
+		return nil
+	})
+	return err
+}
+
+func (s *redisRefreshStore) Rotate(ctx context.Context, familyID, current, next string, ttl time.Duration) (*RefreshFamily, error) {
+	result, err := s.rd.Eval(ctx, rotateScript, []string{s.key(familyID)}, current, next, ttl.Milliseconds()).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := result.(type) {
+	case int64:
+		if v == 1 {
+			return nil, ErrRefreshTokenReused
+		}
+		return nil, ErrRefreshTokenInvalid
+	case string:
+		return &RefreshFamily{Current: next, Claims: []byte(v)}, nil
+	}
+	return nil, fmt.Errorf("unexpected rotate result %T", result)
+}
+
+func (s *redisRefreshStore) Revoke(ctx context.Context, familyID string) error {
+	return s.rd.Del(ctx, s.key(familyID)).Err()
+}
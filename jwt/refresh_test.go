@@ -0,0 +1,177 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/RunzhiZhao/go-mstoolkit/jwt/jwttest"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRefreshStores(t *testing.T) map[string]RefreshStore {
+	mr := miniredis.RunT(t)
+	rd := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rd.Close() })
+
+	return map[string]RefreshStore{
+		"memory": NewMemoryRefreshStore(),
+		"redis":  NewRedisRefreshStore(rd),
+	}
+}
+
+func newTestRefreshManager(t *testing.T, store RefreshStore) (*RefreshManager[testTenantClaims], *ClaimsVerifier[testTenantClaims]) {
+	keys := newTestKeyPairs(t)
+	g, err := NewClaimsGenerator[testTenantClaims](SigningMethodEdDSA, keys[SigningMethodEdDSA].privateKey,
+		WithExpires(15*time.Minute), WithJTI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewClaimsVerifier[testTenantClaims](SigningMethodEdDSA, keys[SigningMethodEdDSA].publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewRefreshManager(g, store, WithRefreshTTL(time.Hour)), v
+}
+
+func TestRefreshManager_Rotation(t *testing.T) {
+	for name, store := range newTestRefreshStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			m, v := newTestRefreshManager(t, store)
+
+			pair, err := m.IssuePair(ctx, testTenantClaims{TenantID: "acme", Scopes: []string{"orders:read"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			first, err := v.Verify(pair.AccessToken)
+			if err != nil || first.TenantID != "acme" {
+				t.Fatalf("Verify() = %+v, %v", first, err)
+			}
+
+			next, err := m.Refresh(ctx, pair.RefreshToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next.RefreshToken == pair.RefreshToken || next.FamilyID != pair.FamilyID {
+				t.Errorf("Refresh() pair = %+v, want rotated token of family %s", next, pair.FamilyID)
+			}
+			got, err := v.Verify(next.AccessToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.TenantID != "acme" || len(got.Scopes) != 1 || got.ID == first.ID {
+				t.Errorf("Verify() refreshed claims = %+v", got)
+			}
+
+			// 新 refresh token 可以继续轮换
+			if _, err := m.Refresh(ctx, next.RefreshToken); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestRefreshManager_ReuseRevokesFamily(t *testing.T) {
+	for name, store := range newTestRefreshStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			m, _ := newTestRefreshManager(t, store)
+
+			pair, err := m.IssuePair(ctx, testTenantClaims{TenantID: "acme"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			other, err := m.IssuePair(ctx, testTenantClaims{TenantID: "acme"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			next, err := m.Refresh(ctx, pair.RefreshToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := m.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) || !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("Refresh() reused error = %v, want %v", err, ErrRefreshTokenReused)
+			}
+			// 整个族已被撤销，最新的 refresh token 同样失效
+			if _, err := m.Refresh(ctx, next.RefreshToken); !errors.Is(err, ErrRefreshTokenInvalid) {
+				t.Errorf("Refresh() after reuse error = %v, want %v", err, ErrRefreshTokenInvalid)
+			}
+			// 其他族不受影响
+			if _, err := m.Refresh(ctx, other.RefreshToken); err != nil {
+				t.Errorf("Refresh() other family error = %v", err)
+			}
+		})
+	}
+}
+
+func TestRefreshManager_Revoke(t *testing.T) {
+	for name, store := range newTestRefreshStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			m, _ := newTestRefreshManager(t, store)
+
+			pair, err := m.IssuePair(ctx, testTenantClaims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := m.Revoke(ctx, pair.RefreshToken); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := m.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrRefreshTokenInvalid) {
+				t.Errorf("Refresh() error = %v, want %v", err, ErrRefreshTokenInvalid)
+			}
+			for _, bad := range []string{"", "nodot", ".secret", "family."} {
+				if _, err := m.Refresh(ctx, bad); !errors.Is(err, ErrRefreshTokenInvalid) {
+					t.Errorf("Refresh(%q) error = %v, want %v", bad, err, ErrRefreshTokenInvalid)
+				}
+			}
+		})
+	}
+}
+
+func TestRedisRefreshStore_Expiry(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rd := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rd.Close()
+	ctx := context.Background()
+	m, _ := newTestRefreshManager(t, NewRedisRefreshStore(rd, WithRefreshKeyPrefix("test:refresh")))
+
+	pair, err := m.IssuePair(ctx, testTenantClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mr.Exists("test:refresh:" + pair.FamilyID) {
+		t.Fatalf("family key not found")
+	}
+	mr.FastForward(2 * time.Hour)
+	if _, err := m.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Errorf("Refresh() expired error = %v, want %v", err, ErrRefreshTokenInvalid)
+	}
+}
+
+func TestMemoryRefreshStore_Expiry(t *testing.T) {
+	ctx := context.Background()
+	clock := jwttest.NewFakeClock(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := NewMemoryRefreshStore(WithStoreClock(clock))
+
+	if err := store.Create(ctx, "f1", RefreshFamily{Current: "a"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Hour - time.Second)
+	if _, err := store.Rotate(ctx, "f1", "a", "b", time.Hour); err != nil {
+		t.Fatalf("Rotate() before exp error = %v", err)
+	}
+	// Rotate 按时钟续期
+	clock.Advance(time.Hour - time.Second)
+	if _, err := store.Rotate(ctx, "f1", "b", "c", time.Hour); err != nil {
+		t.Fatalf("Rotate() after renewal error = %v", err)
+	}
+	clock.Advance(time.Hour)
+	if _, err := store.Rotate(ctx, "f1", "c", "d", time.Hour); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Errorf("Rotate() expired error = %v, want %v", err, ErrRefreshTokenInvalid)
+	}
+}
@@ -0,0 +1,240 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrJWKSFetch = errors.New("failed to fetch jwks")
+)
+
+const (
+	defaultJWKSRefreshInterval = 15 * time.Minute
+	defaultJWKSRefetchInterval = 30 * time.Second
+	defaultJWKSFetchTimeout    = 10 * time.Second
+	defaultJWKSRefetchTimeout  = 2 * time.Second
+	maxJWKSBodySize            = 1 << 20
+)
+
+// RemoteOption is a remote token verifier option
+type RemoteOption func(*remoteKeySet)
+
+// WithHTTPClient set the http client used to fetch jwks
+func WithHTTPClient(client *http.Client) RemoteOption {
+	return func(r *remoteKeySet) {
+		r.client = client
+	}
+}
+
+// WithRefreshInterval set the interval of background refresh
+func WithRefreshInterval(interval time.Duration) RemoteOption {
+	return func(r *remoteKeySet) {
+		r.refreshInterval = interval
+	}
+}
+
+// WithRefetchInterval set the minimum interval between two refetches triggered by unknown kid
+func WithRefetchInterval(interval time.Duration) RemoteOption {
+	return func(r *remoteKeySet) {
+		r.refetchInterval = interval
+	}
+}
+
+// WithRefetchTimeout bound the refetch triggered by an unknown kid, which blocks the Verify call, default is 2s
+func WithRefetchTimeout(timeout time.Duration) RemoteOption {
+	return func(r *remoteKeySet) {
+		r.refetchTimeout = timeout
+	}
+}
+
+// WithVerifierOptions set the issuer/audience/leeway/max age checks of the remote verifier,
+// WithWeakVerificationKey also accepts weak keys from the JWK Set
+func WithVerifierOptions(opts ...VerifierOption) RemoteOption {
+	return func(r *remoteKeySet) {
+		r.verifierOpts = append(r.verifierOpts, opts...)
+	}
+}
+
+// remoteKeySet 从 JWKS 地址拉取公钥并缓存到 KeyRing 中，
+// 拉取失败时继续使用上一次成功拉取的密钥
+type remoteKeySet struct {
+	url             string
+	client          *http.Client
+	ring            *KeyRing
+	refreshInterval time.Duration
+	refetchInterval time.Duration
+	refetchTimeout  time.Duration
+	verifierOpts    []VerifierOption
+	allowWeakKey    bool
+
+	mu        sync.Mutex
+	lastFetch time.Time
+}
+
+// NewRemoteTokenVerifier new a token verifier backed by the JWK Set at jwksURL.
+// 密钥按 token 的 kid 选择；后台每隔 refresh interval 刷新一次，直到 ctx 结束；
+// 遇到未知 kid 时立即重新拉取（受 refetch interval 限流）；
+// 拉取失败时继续使用上一次成功拉取的密钥。首次拉取失败时返回错误
+func NewRemoteTokenVerifier(ctx context.Context, jwksURL string, opts ...RemoteOption) (*TokenVerifier, error) {
+	v, err := NewRemoteClaimsVerifier[TokenClaims](ctx, jwksURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenVerifier{claims: v}, nil
+}
+
+// NewRemoteClaimsVerifier new a token verifier for custom claims T backed by the JWK Set at jwksURL,
+// see NewRemoteTokenVerifier
+func NewRemoteClaimsVerifier[T jwt.Claims](ctx context.Context, jwksURL string, opts ...RemoteOption) (*ClaimsVerifier[T], error) {
+	r := &remoteKeySet{
+		url:             jwksURL,
+		client:          &http.Client{Timeout: defaultJWKSFetchTimeout},
+		ring:            &KeyRing{},
+		refreshInterval: defaultJWKSRefreshInterval,
+		refetchInterval: defaultJWKSRefetchInterval,
+		refetchTimeout:  defaultJWKSRefetchTimeout,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	v := newVerifier(nil, r.ring, r, r.verifierOpts)
+	if v.err != nil {
+		return nil, v.err
+	}
+	r.allowWeakKey = v.allowWeakKey
+
+	r.lastFetch = time.Now()
+	if err := r.fetch(ctx); err != nil {
+		return nil, err
+	}
+	go r.refreshLoop(ctx)
+
+	return &ClaimsVerifier[T]{verifier: v}, nil
+}
+
+func (r *remoteKeySet) refreshLoop(ctx context.Context) {
+	ticker := time.NewTicker(r.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.mu.Lock()
+			r.lastFetch = time.Now()
+			r.mu.Unlock()
+			// 失败时保留上一次的密钥
+			_ = r.fetch(ctx)
+		}
+	}
+}
+
+// refetch 遇到未知 kid 时重新拉取，距离上次拉取不足 refetch interval 时直接返回 false。
+// 拉取在 Verify 中同步进行，最多等待 refetch timeout
+func (r *remoteKeySet) refetch() bool {
+	r.mu.Lock()
+	if time.Since(r.lastFetch) < r.refetchInterval {
+		r.mu.Unlock()
+		return false
+	}
+	r.lastFetch = time.Now()
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.refetchTimeout)
+	defer cancel()
+	return r.fetch(ctx) == nil
+}
+
+func (r *remoteKeySet) fetch(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrJWKSFetch, err)
+	}
+	req.Header.Set("Accept", "application/jwk-set+json, application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrJWKSFetch, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: status %d", ErrJWKSFetch, resp.StatusCode)
+	}
+
+	var jwks JWKSet
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxJWKSBodySize)).Decode(&jwks); err != nil {
+		return fmt.Errorf("%w: %v", ErrJWKSFetch, err)
+	}
+
+	set, err := newRemoteKeySet(jwks, r.allowWeakKey)
+	if err != nil {
+		return err
+	}
+	r.ring.set.Store(set)
+	return nil
+}
+
+// newRemoteKeySet 将 JWK Set 转换为密钥环快照，跳过非签名用途、不支持的密钥，
+// 以及未开启 allowWeak 时强度不足的密钥
+func newRemoteKeySet(jwks JWKSet, allowWeak bool) (*keySet, error) {
+	set := &keySet{verifier: make(map[string]*ringKey)}
+	var weakErr error
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		method, err := jwk.signingMethod()
+		if err != nil {
+			continue
+		}
+		pk, err := jwk.PublicKey()
+		if err != nil || !method.acceptsPublicKey(pk) {
+			continue
+		}
+		if !allowWeak {
+			if err := checkKeyStrength(method, pk); err != nil {
+				weakErr = fmt.Errorf("key %s: %w", jwk.Kid, err)
+				continue
+			}
+		}
+		set.verifier[jwk.Kid] = &ringKey{
+			id:     jwk.Kid,
+			parser: &Parser{signingMethod: method, publicKey: pk},
+		}
+	}
+	if len(set.verifier) == 0 {
+		if weakErr != nil {
+			return nil, fmt.Errorf("%w: no usable keys: %w", ErrJWKSFetch, weakErr)
+		}
+		return nil, fmt.Errorf("%w: no usable keys", ErrJWKSFetch)
+	}
+	return set, nil
+}
+
+// acceptsPublicKey 公钥类型（及 EC 曲线）是否与签名方法匹配
+func (m SigningMethod) acceptsPublicKey(pk interface{}) bool {
+	switch pk := pk.(type) {
+	case ed25519.PublicKey:
+		return m == SigningMethodEdDSA
+	case *rsa.PublicKey:
+		return m == SigningMethodRS256 || m == SigningMethodRS384 || m == SigningMethodRS512
+	case *ecdsa.PublicKey:
+		return m.matchesCurve(pk.Curve)
+	}
+	return false
+}
@@ -0,0 +1,255 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// jwksTestServer 发布 ring 的 JWK Set，可以模拟故障并统计请求次数
+type jwksTestServer struct {
+	*httptest.Server
+	requests atomic.Int32
+	failing  atomic.Bool
+}
+
+func newJWKSTestServer(t *testing.T, ring *KeyRing) *jwksTestServer {
+	s := &jwksTestServer{}
+	handler := NewJWKSHandler(ring)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		if s.failing.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestRing(t *testing.T, ids ...string) (*KeyRing, []Key) {
+	keys := newTestKeyPairs(t)
+	methods := []SigningMethod{SigningMethodEdDSA, SigningMethodES256, SigningMethodRS256}
+	var ringKeys []Key
+	for i, id := range ids {
+		m := methods[i%len(methods)]
+		state := KeyVerifyOnly
+		if i == 0 {
+			state = KeyActive
+		}
+		ringKeys = append(ringKeys, Key{ID: id, SigningMethod: m, State: state,
+			PrivateKey: keys[m].privateKey, PublicKey: keys[m].publicKey})
+	}
+	ring, err := NewKeyRing(ringKeys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ring, ringKeys
+}
+
+func TestRemoteTokenVerifier_Rotation(t *testing.T) {
+	ring, ringKeys := newTestRing(t, "k1", "k2")
+	srv := newJWKSTestServer(t, ring)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	v, err := NewRemoteTokenVerifier(ctx, srv.URL+JWKSPath, WithRefetchInterval(0))
+	if err != nil {
+		t.Fatalf("NewRemoteTokenVerifier() error = %v", err)
+	}
+	g := NewTokenGeneratorWithKeyRing(ring)
+
+	t1, _ := g.Generate(TokenInfo{UserID: 1})
+	if got, err := v.Verify(t1); err != nil || got.UserID != 1 {
+		t.Fatalf("Verify() = %v, %v", got, err)
+	}
+
+	// 发布端新增并启用 k3，verifier 遇到未知 kid 时重新拉取
+	k3 := newTestRingKey(t, "k3", SigningMethodES256)
+	ringKeys[0].State = KeyVerifyOnly
+	if err := ring.Replace(append(ringKeys, k3)...); err != nil {
+		t.Fatal(err)
+	}
+	t3, _ := g.Generate(TokenInfo{UserID: 3})
+	before := srv.requests.Load()
+	if got, err := v.Verify(t3); err != nil || got.UserID != 3 {
+		t.Fatalf("Verify() = %v, %v", got, err)
+	}
+	if srv.requests.Load() != before+1 {
+		t.Errorf("requests = %d, want %d", srv.requests.Load(), before+1)
+	}
+}
+
+func TestRemoteTokenVerifier_RefetchRateLimited(t *testing.T) {
+	ring, _ := newTestRing(t, "k1")
+	srv := newJWKSTestServer(t, ring)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	v, err := NewRemoteTokenVerifier(ctx, srv.URL, WithRefetchInterval(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forged := NewTokenGeneratorWithKeyRing(mustRing(t, newTestRingKey(t, "unknown", SigningMethodEdDSA)))
+	tokenStr, _ := forged.Generate(TokenInfo{UserID: 1})
+	for i := 0; i < 5; i++ {
+		if _, err := v.Verify(tokenStr); !errors.Is(err, ErrUnknownKeyID) {
+			t.Fatalf("Verify() error = %v, want %v", err, ErrUnknownKeyID)
+		}
+	}
+	if n := srv.requests.Load(); n != 1 {
+		t.Errorf("requests = %d, want 1", n)
+	}
+}
+
+func TestRemoteTokenVerifier_LastKnownGood(t *testing.T) {
+	ring, _ := newTestRing(t, "k1")
+	srv := newJWKSTestServer(t, ring)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	v, err := NewRemoteTokenVerifier(ctx, srv.URL, WithRefreshInterval(5*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenStr, _ := NewTokenGeneratorWithKeyRing(ring).Generate(TokenInfo{UserID: 1})
+
+	srv.failing.Store(true)
+	deadline := time.Now().Add(time.Second)
+	for srv.requests.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if srv.requests.Load() < 3 {
+		t.Fatal("background refresh did not run")
+	}
+	if _, err := v.Verify(tokenStr); err != nil {
+		t.Errorf("Verify() error = %v, want last known good keys", err)
+	}
+}
+
+func TestNewRemoteTokenVerifier_InitialFetchFailed(t *testing.T) {
+	ring, _ := newTestRing(t, "k1")
+	srv := newJWKSTestServer(t, ring)
+	srv.failing.Store(true)
+
+	if _, err := NewRemoteTokenVerifier(context.Background(), srv.URL); !errors.Is(err, ErrJWKSFetch) {
+		t.Errorf("NewRemoteTokenVerifier() error = %v, want %v", err, ErrJWKSFetch)
+	}
+}
+
+func newTestRingKey(t *testing.T, id string, m SigningMethod) Key {
+	keys := generateTestKeyPairs(t)
+	return Key{ID: id, SigningMethod: m, State: KeyActive, PrivateKey: keys[m].privateKey, PublicKey: keys[m].publicKey}
+}
+
+func mustRing(t *testing.T, keys ...Key) *KeyRing {
+	ring, err := NewKeyRing(keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ring
+}
+
+func TestRemoteTokenVerifier_WeakKeys(t *testing.T) {
+	rsa1024, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(rsa1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDer, err := x509.MarshalPKIXPublicKey(&rsa1024.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weak := Key{ID: "weak", SigningMethod: SigningMethodRS256, State: KeyActive, AllowWeakKey: true,
+		PrivateKey: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+		PublicKey:  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDer})}
+	strong := newTestRingKey(t, "strong", SigningMethodES256)
+	strong.State = KeyVerifyOnly
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 只有弱密钥时首次拉取失败
+	srv := newJWKSTestServer(t, mustRing(t, weak))
+	if _, err := NewRemoteTokenVerifier(ctx, srv.URL); !errors.Is(err, ErrJWKSFetch) || !errors.Is(err, ErrRSAKeyTooSmall) {
+		t.Fatalf("NewRemoteTokenVerifier() error = %v, want %v wrapping %v", err, ErrJWKSFetch, ErrRSAKeyTooSmall)
+	}
+
+	// 跳过弱密钥，其余密钥照常使用
+	ring := mustRing(t, weak, strong)
+	srv = newJWKSTestServer(t, ring)
+	weakToken, err := NewTokenGeneratorWithKeyRing(ring).Generate(TokenInfo{UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewRemoteTokenVerifier(ctx, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Verify(weakToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify() token signed by a weak key error = %v, want %v", err, ErrInvalidToken)
+	}
+	if _, err := v.claims.keyRing.verificationKey("strong"); err != nil {
+		t.Errorf("strong key not loaded: %v", err)
+	}
+
+	// WithWeakVerificationKey 放行弱密钥
+	v, err = NewRemoteTokenVerifier(ctx, srv.URL, WithVerifierOptions(WithWeakVerificationKey()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Verify(weakToken); err != nil {
+		t.Errorf("Verify() with WithWeakVerificationKey error = %v", err)
+	}
+}
+
+func TestRemoteTokenVerifier_RefetchTimeout(t *testing.T) {
+	ring, _ := newTestRing(t, "k1")
+	handler := NewJWKSHandler(ring)
+	var requests atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) > 1 {
+			<-release // 首次拉取之后端点不再响应
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	v, err := NewRemoteTokenVerifier(ctx, srv.URL, WithRefetchInterval(0), WithRefetchTimeout(20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := newTestRing(t, "unknown")
+	tokenStr, err := NewTokenGeneratorWithKeyRing(other).Generate(TokenInfo{UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if _, err := v.Verify(tokenStr); !errors.Is(err, ErrUnknownKeyID) {
+		t.Errorf("Verify() error = %v, want %v", err, ErrUnknownKeyID)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Verify() took %v, want bounded by the refetch timeout", elapsed)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("requests = %d, want 2", n)
+	}
+}
@@ -0,0 +1,207 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrTokenRevoked token 的 jti 已被吊销，或其 subject 吊销了此前签发的所有 token
+	ErrTokenRevoked = fmt.Errorf("%w: token is revoked", ErrInvalidToken)
+)
+
+const (
+	defaultRevocationCacheTTL = 10 * time.Second
+	defaultRevocationTimeout  = time.Second
+)
+
+// RevocationStore stores revoked token ids and per-subject revocation times,
+// entries can be dropped once the affected tokens have expired
+type RevocationStore interface {
+	// RevokeToken 吊销 jti，记录保留到 expiresAt（通常为 token 的 exp）
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	// RevokeSubject 吊销 subject 在 before 之前签发的所有 token，记录保留到 expiresAt（通常为 before 加上 token 有效期）
+	RevokeSubject(ctx context.Context, subject string, before, expiresAt time.Time) error
+	// TokenRevoked jti 是否已被吊销
+	TokenRevoked(ctx context.Context, jti string) (bool, error)
+	// SubjectRevokedBefore 返回 subject 的吊销时间，未吊销时返回零值
+	SubjectRevokedBefore(ctx context.Context, subject string) (time.Time, error)
+}
+
+// WithRevocationStore reject tokens revoked in store, checked by jti and by subject + iat
+func WithRevocationStore(store RevocationStore) VerifierOption {
+	return func(v *verifier) {
+		v.revocationStore = store
+	}
+}
+
+// WithRevocationCacheTTL set how long lookups of the revocation store are cached locally, default is 10s.
+// 吊销在 ttl 内可能尚未生效，0 表示不缓存
+func WithRevocationCacheTTL(ttl time.Duration) VerifierOption {
+	return func(v *verifier) {
+		v.revocationCacheTTL = ttl
+	}
+}
+
+// WithRevocationTimeout bound the lookups of the revocation store for one verification, default is 1s.
+// 超时返回存储的错误（context.DeadlineExceeded），不会当作 token 无效；0 表示不限制
+func WithRevocationTimeout(timeout time.Duration) VerifierOption {
+	return func(v *verifier) {
+		v.revocationTimeout = timeout
+	}
+}
+
+type revocationCacheEntry struct {
+	revoked   bool      // jti 是否已吊销
+	before    time.Time // subject 的吊销时间
+	expiresAt time.Time
+}
+
+// revocationChecker 查询 RevocationStore，并在本地缓存查询结果
+type revocationChecker struct {
+	store    RevocationStore
+	cacheTTL time.Duration
+	timeout  time.Duration
+	clock    Clock
+
+	mu        sync.Mutex
+	tokens    map[string]revocationCacheEntry
+	subjects  map[string]revocationCacheEntry
+	lastSweep time.Time
+}
+
+func newRevocationChecker(store RevocationStore, cacheTTL, timeout time.Duration, clock Clock) *revocationChecker {
+	return &revocationChecker{
+		store:     store,
+		cacheTTL:  cacheTTL,
+		timeout:   timeout,
+		clock:     clock,
+		tokens:    make(map[string]revocationCacheEntry),
+		subjects:  make(map[string]revocationCacheEntry),
+		lastSweep: clock.Now(),
+	}
+}
+
+// check 返回 ErrTokenRevoked 对应的 VerificationError，查询存储失败时返回存储的错误
+func (c *revocationChecker) check(ctx context.Context, claims jwt.Claims) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	if jti := claimsID(claims); jti != "" {
+		revoked, err := c.tokenRevoked(ctx, jti)
+		if err != nil {
+			return fmt.Errorf("check token revocation: %w", err)
+		}
+		if revoked {
+			return newVerificationError(ReasonRevoked, nil)
+		}
+	}
+
+	sub, err := claims.GetSubject()
+	if err != nil || sub == "" {
+		return nil
+	}
+	before, err := c.subjectRevokedBefore(ctx, sub)
+	if err != nil {
+		return fmt.Errorf("check subject revocation: %w", err)
+	}
+	if before.IsZero() {
+		return nil
+	}
+	iat, err := claims.GetIssuedAt()
+	if err != nil || iat == nil || iat.Before(before) {
+		// 缺少 iat 时无法证明签发于吊销之后
+		return newVerificationError(ReasonRevoked, nil)
+	}
+	return nil
+}
+
+func (c *revocationChecker) tokenRevoked(ctx context.Context, jti string) (bool, error) {
+	if e, ok := c.cached(c.tokens, jti); ok {
+		return e.revoked, nil
+	}
+	revoked, err := c.store.TokenRevoked(ctx, jti)
+	if err != nil {
+		return false, err
+	}
+	c.cache(c.tokens, jti, revocationCacheEntry{revoked: revoked})
+	return revoked, nil
+}
+
+func (c *revocationChecker) subjectRevokedBefore(ctx context.Context, sub string) (time.Time, error) {
+	if e, ok := c.cached(c.subjects, sub); ok {
+		return e.before, nil
+	}
+	before, err := c.store.SubjectRevokedBefore(ctx, sub)
+	if err != nil {
+		return time.Time{}, err
+	}
+	c.cache(c.subjects, sub, revocationCacheEntry{before: before})
+	return before, nil
+}
+
+func (c *revocationChecker) cached(m map[string]revocationCacheEntry, key string) (revocationCacheEntry, bool) {
+	if c.cacheTTL <= 0 {
+		return revocationCacheEntry{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := m[key]
+	if !ok || !c.clock.Now().Before(e.expiresAt) {
+		return revocationCacheEntry{}, false
+	}
+	return e, true
+}
+
+func (c *revocationChecker) cache(m map[string]revocationCacheEntry, key string, e revocationCacheEntry) {
+	if c.cacheTTL <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	if now.Sub(c.lastSweep) >= c.cacheTTL {
+		// 每个 ttl 周期清理一次过期缓存
+		for _, m := range []map[string]revocationCacheEntry{c.tokens, c.subjects} {
+			for k, e := range m {
+				if !now.Before(e.expiresAt) {
+					delete(m, k)
+				}
+			}
+		}
+		c.lastSweep = now
+	}
+	e.expiresAt = now.Add(c.cacheTTL)
+	m[key] = e
+}
+
+// claimsID 返回 claims 的 jti，claims 为 jwt.MapClaims 或嵌入了 jwt.RegisteredClaims 的结构体（指针）
+func claimsID(claims jwt.Claims) string {
+	switch c := claims.(type) {
+	case jwt.MapClaims:
+		jti, _ := c["jti"].(string)
+		return jti
+	case *jwt.RegisteredClaims:
+		return c.ID
+	}
+
+	rv := reflect.ValueOf(claims)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return ""
+	}
+	index, ok := registeredClaimsIndex(rv.Elem().Type())
+	if !ok {
+		return ""
+	}
+	return rv.Elem().FieldByIndex(index).Interface().(jwt.RegisteredClaims).ID
+}
@@ -0,0 +1,89 @@
+package jwt
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryRevocation struct {
+	before    time.Time
+	expiresAt time.Time
+}
+
+// memoryRevocationStore 进程内的 RevocationStore，适用于单实例部署和测试
+type memoryRevocationStore struct {
+	mu        sync.Mutex
+	tokens    map[string]memoryRevocation
+	subjects  map[string]memoryRevocation
+	clock     Clock
+	lastSweep time.Time
+}
+
+// NewMemoryRevocationStore new an in-memory revocation store
+func NewMemoryRevocationStore(opts ...MemoryStoreOption) RevocationStore {
+	o := newMemoryStoreOptions(opts)
+	return &memoryRevocationStore{
+		tokens:    make(map[string]memoryRevocation),
+		subjects:  make(map[string]memoryRevocation),
+		clock:     o.clock,
+		lastSweep: o.clock.Now(),
+	}
+}
+
+func (s *memoryRevocationStore) RevokeToken(_ context.Context, jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep()
+	if s.clock.Now().Before(expiresAt) {
+		s.tokens[jti] = memoryRevocation{expiresAt: expiresAt}
+	}
+	return nil
+}
+
+func (s *memoryRevocationStore) RevokeSubject(_ context.Context, subject string, before, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep()
+	if s.clock.Now().Before(expiresAt) {
+		s.subjects[subject] = memoryRevocation{before: before, expiresAt: expiresAt}
+	}
+	return nil
+}
+
+func (s *memoryRevocationStore) TokenRevoked(_ context.Context, jti string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.tokens[jti]
+	return ok && s.clock.Now().Before(r.expiresAt), nil
+}
+
+func (s *memoryRevocationStore) SubjectRevokedBefore(_ context.Context, subject string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.subjects[subject]
+	if !ok || !s.clock.Now().Before(r.expiresAt) {
+		return time.Time{}, nil
+	}
+	return r.before, nil
+}
+
+// sweep 定期清理过期的记录，调用方需持有锁
+func (s *memoryRevocationStore) sweep() {
+	now := s.clock.Now()
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	for _, m := range []map[string]memoryRevocation{s.tokens, s.subjects} {
+		for k, r := range m {
+			if !now.Before(r.expiresAt) {
+				delete(m, k)
+			}
+		}
+	}
+	s.lastSweep = now
+}
@@ -0,0 +1,92 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultRevocationKeyPrefix = "jwt:revoked"
+
+// RedisRevocationStoreOption is a redis revocation store option
+type RedisRevocationStoreOption func(*redisRevocationStore)
+
+// WithRevocationKeyPrefix set the key prefix of revocation entries, default is "jwt:revoked"
+func WithRevocationKeyPrefix(keyPrefix string) RedisRevocationStoreOption {
+	return func(s *redisRevocationStore) {
+		s.keyPrefix = keyPrefix
+	}
+}
+
+// redisRevocationStore 基于 Redis 的 RevocationStore，
+// jti 存为 prefix:jti:<jti>，subject 的吊销时间（unix 毫秒）存为 prefix:sub:<subject>，均在 expiresAt 过期
+type redisRevocationStore struct {
+	rd        *redis.Client
+	keyPrefix string
+}
+
+// NewRedisRevocationStore new a revocation store backed by redis
+func NewRedisRevocationStore(rd *redis.Client, opts ...RedisRevocationStoreOption) RevocationStore {
+	s := &redisRevocationStore{
+		rd:        rd,
+		keyPrefix: defaultRevocationKeyPrefix,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *redisRevocationStore) tokenKey(jti string) string {
+	return fmt.Sprintf("%s:jti:%s", s.keyPrefix, jti)
+}
+
+func (s *redisRevocationStore) subjectKey(subject string) string {
+	return fmt.Sprintf("%s:sub:%s", s.keyPrefix, subject)
+}
+
+func (s *redisRevocationStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		// token 已过期，无需记录
+		return nil
+	}
+	return s.rd.Set(ctx, s.tokenKey(jti), 1, ttl).Err()
+}
+
+func (s *redisRevocationStore) RevokeSubject(ctx context.Context, subject string, before, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.rd.Set(ctx, s.subjectKey(subject), before.UnixMilli(), ttl).Err()
+}
+
+func (s *redisRevocationStore) TokenRevoked(ctx context.Context, jti string) (bool, error) {
+	n, err := s.rd.Exists(ctx, s.tokenKey(jti)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (s *redisRevocationStore) SubjectRevokedBefore(ctx context.Context, subject string) (time.Time, error) {
+	v, err := s.rd.Get(ctx, s.subjectKey(subject)).Result()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	ms, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(ms), nil
+}
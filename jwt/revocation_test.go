@@ -0,0 +1,239 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RunzhiZhao/go-mstoolkit/jwt/jwttest"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRevocationStores(t *testing.T) map[string]RevocationStore {
+	mr := miniredis.RunT(t)
+	rd := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rd.Close() })
+
+	return map[string]RevocationStore{
+		"memory": NewMemoryRevocationStore(),
+		"redis":  NewRedisRevocationStore(rd),
+	}
+}
+
+func TestVerifier_Revocation(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	// 存储按真实时间计算 TTL，时钟从当前时间开始
+	clock := jwttest.NewFakeClock(time.Now())
+	g, err := NewClaimsGenerator[TokenClaims](SigningMethodHS256, keys[SigningMethodHS256].privateKey, WithJTI(), WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(rc jwt.RegisteredClaims) (string, jwt.RegisteredClaims) {
+		c := TokenClaims{RegisteredClaims: rc}
+		tokenStr, err := g.Generate(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		claims, err := NewClaimsVerifier[TokenClaims](SigningMethodHS256, keys[SigningMethodHS256].publicKey, WithVerifierClock(clock))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := claims.Verify(tokenStr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tokenStr, got.RegisteredClaims
+	}
+
+	for name, store := range newTestRevocationStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			v, err := NewTokenVerifier(SigningMethodHS256, keys[SigningMethodHS256].publicKey,
+				WithRevocationStore(store), WithRevocationCacheTTL(0), WithVerifierClock(clock))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			revoked, rc := sign(jwt.RegisteredClaims{Subject: "alice"})
+			kept, _ := sign(jwt.RegisteredClaims{Subject: "alice"})
+			if err := store.RevokeToken(ctx, rc.ID, rc.ExpiresAt.Time); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := v.Verify(revoked); !errors.Is(err, ErrTokenRevoked) || ReasonOf(err) != ReasonRevoked {
+				t.Errorf("Verify() revoked jti error = %v, want %v", err, ErrTokenRevoked)
+			}
+			if _, err := v.Verify(kept); err != nil {
+				t.Errorf("Verify() other jti error = %v", err)
+			}
+
+			// 吊销 alice 此前签发的所有 token
+			clock.Advance(time.Second)
+			before := clock.Now()
+			if err := store.RevokeSubject(ctx, "alice", before, before.Add(time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := v.Verify(kept); !errors.Is(err, ErrTokenRevoked) {
+				t.Errorf("Verify() issued before revocation error = %v, want %v", err, ErrTokenRevoked)
+			}
+			clock.Advance(time.Second)
+			after, _ := sign(jwt.RegisteredClaims{Subject: "alice"})
+			if _, err := v.Verify(after); err != nil {
+				t.Errorf("Verify() issued after revocation error = %v", err)
+			}
+			bob, _ := sign(jwt.RegisteredClaims{Subject: "bob"})
+			if _, err := v.Verify(bob); err != nil {
+				t.Errorf("Verify() other subject error = %v", err)
+			}
+		})
+	}
+}
+
+// countingRevocationStore 统计查询次数
+type countingRevocationStore struct {
+	RevocationStore
+	lookups atomic.Int32
+}
+
+func (s *countingRevocationStore) TokenRevoked(ctx context.Context, jti string) (bool, error) {
+	s.lookups.Add(1)
+	return s.RevocationStore.TokenRevoked(ctx, jti)
+}
+
+func TestVerifier_RevocationCache(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	g, err := NewTokenGenerator(SigningMethodHS256, keys[SigningMethodHS256].privateKey, WithJTI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &countingRevocationStore{RevocationStore: NewMemoryRevocationStore()}
+	clock := jwttest.NewFakeClock(time.Now())
+	v, err := NewTokenVerifier(SigningMethodHS256, keys[SigningMethodHS256].publicKey,
+		WithRevocationStore(store), WithRevocationCacheTTL(time.Minute), WithVerifierClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenStr, err := g.Generate(TokenInfo{UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := v.Verify(tokenStr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := store.lookups.Load(); n != 1 {
+		t.Errorf("store lookups = %d, want 1", n)
+	}
+
+	// 缓存过期后重新查询
+	clock.Advance(time.Minute)
+	if _, err := v.Verify(tokenStr); err != nil {
+		t.Fatal(err)
+	}
+	if n := store.lookups.Load(); n != 2 {
+		t.Errorf("store lookups after cache ttl = %d, want 2", n)
+	}
+}
+
+func TestRedisRevocationStore_Expiry(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rd := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rd.Close()
+	ctx := context.Background()
+	store := NewRedisRevocationStore(rd, WithRevocationKeyPrefix("test:revoked"))
+
+	if err := store.RevokeToken(ctx, "jti-1", time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RevokeToken(ctx, "jti-expired", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if !mr.Exists("test:revoked:jti:jti-1") || mr.Exists("test:revoked:jti:jti-expired") {
+		t.Fatalf("unexpected keys %v", mr.Keys())
+	}
+
+	mr.FastForward(2 * time.Minute)
+	if revoked, err := store.TokenRevoked(ctx, "jti-1"); err != nil || revoked {
+		t.Errorf("TokenRevoked() after exp = %v, %v", revoked, err)
+	}
+}
+
+func TestMemoryRevocationStore_Expiry(t *testing.T) {
+	ctx := context.Background()
+	clock := jwttest.NewFakeClock(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := NewMemoryRevocationStore(WithStoreClock(clock))
+
+	if err := store.RevokeToken(ctx, "jti-1", clock.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	before := clock.Now()
+	if err := store.RevokeSubject(ctx, "u1", before, clock.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if revoked, err := store.TokenRevoked(ctx, "jti-1"); err != nil || !revoked {
+		t.Errorf("TokenRevoked() = %v, %v, want revoked", revoked, err)
+	}
+	if got, err := store.SubjectRevokedBefore(ctx, "u1"); err != nil || !got.Equal(before) {
+		t.Errorf("SubjectRevokedBefore() = %v, %v, want %v", got, err, before)
+	}
+
+	clock.Advance(time.Minute)
+	if revoked, err := store.TokenRevoked(ctx, "jti-1"); err != nil || revoked {
+		t.Errorf("TokenRevoked() after exp = %v, %v", revoked, err)
+	}
+	if got, err := store.SubjectRevokedBefore(ctx, "u1"); err != nil || !got.IsZero() {
+		t.Errorf("SubjectRevokedBefore() after exp = %v, %v", got, err)
+	}
+}
+
+// blockingRevocationStore 查询一直阻塞到 ctx 结束
+type blockingRevocationStore struct {
+	RevocationStore
+}
+
+func (blockingRevocationStore) TokenRevoked(ctx context.Context, jti string) (bool, error) {
+	<-ctx.Done()
+	return false, ctx.Err()
+}
+
+func TestVerifier_RevocationTimeout(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	g, err := NewTokenGenerator(SigningMethodHS256, keys[SigningMethodHS256].privateKey, WithJTI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenStr, err := g.Generate(TokenInfo{UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := blockingRevocationStore{RevocationStore: NewMemoryRevocationStore()}
+	v, err := NewTokenVerifier(SigningMethodHS256, keys[SigningMethodHS256].publicKey,
+		WithRevocationStore(store), WithRevocationTimeout(20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	_, err = v.Verify(tokenStr)
+	if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Verify() error = %v, want a store timeout not wrapping ErrInvalidToken", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Verify() took %v, want bounded by the revocation timeout", elapsed)
+	}
+
+	// 默认超时同样生效
+	v, err = NewTokenVerifier(SigningMethodHS256, keys[SigningMethodHS256].publicKey, WithRevocationStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.claims.revocation.timeout != defaultRevocationTimeout {
+		t.Errorf("default revocation timeout = %v, want %v", v.claims.revocation.timeout, defaultRevocationTimeout)
+	}
+}
@@ -1,6 +1,7 @@
 package jwt
 
 import (
+	"crypto/elliptic"
 	"errors"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -46,34 +47,14 @@ type Signer struct {
 	privateKey    interface{}
 }
 
-func newSigner(signingMethod SigningMethod, pkByte []byte) (*Signer, error) {
-	var privateKey interface{}
-	var err error
-	var jwtSigningMethod jwt.SigningMethod
-
-	switch signingMethod {
-	case SigningMethodEdDSA:
-		jwtSigningMethod = jwt.SigningMethodEdDSA
-		privateKey, err = jwt.ParseEdPrivateKeyFromPEM(pkByte)
-
-	case SigningMethodRS256, SigningMethodRS384, SigningMethodRS512:
-		jwtSigningMethod = jwt.SigningMethodRS256
-		privateKey, err = jwt.ParseRSAPrivateKeyFromPEM(pkByte)
-
-	case SigningMethodES256, SigningMethodES384, SigningMethodES512:
-		jwtSigningMethod = jwt.SigningMethodES256
-		privateKey, err = jwt.ParseECPrivateKeyFromPEM(pkByte)
-
-	case SigningMethodHS256, SigningMethodHS384, SigningMethodHS512:
-		jwtSigningMethod = jwt.SigningMethodHS256
-		privateKey = pkByte
-
-	default:
+func newSigner(signingMethod SigningMethod, pkByte []byte, allowWeak bool) (*Signer, error) {
+	jwtSigningMethod := signingMethod.jwtMethod()
+	if jwtSigningMethod == nil {
 		return nil, ErrUnknownSigningMethod
 	}
-
+	privateKey, err := parseSigningKey(signingMethod, pkByte, allowWeak)
 	if err != nil {
-		return nil, ErrInvalidPrivateKey
+		return nil, err
 	}
 
 	return &Signer{
@@ -81,3 +62,69 @@ func newSigner(signingMethod SigningMethod, pkByte []byte) (*Signer, error) {
 		privateKey:    privateKey,
 	}, nil
 }
+
+// jwtMethod 返回与 SigningMethod 完全对应的 jwt.SigningMethod
+func (m SigningMethod) jwtMethod() jwt.SigningMethod {
+	switch m {
+	case SigningMethodEdDSA:
+		return jwt.SigningMethodEdDSA
+	case SigningMethodRS256:
+		return jwt.SigningMethodRS256
+	case SigningMethodRS384:
+		return jwt.SigningMethodRS384
+	case SigningMethodRS512:
+		return jwt.SigningMethodRS512
+	case SigningMethodES256:
+		return jwt.SigningMethodES256
+	case SigningMethodES384:
+		return jwt.SigningMethodES384
+	case SigningMethodES512:
+		return jwt.SigningMethodES512
+	case SigningMethodHS256:
+		return jwt.SigningMethodHS256
+	case SigningMethodHS384:
+		return jwt.SigningMethodHS384
+	case SigningMethodHS512:
+		return jwt.SigningMethodHS512
+	}
+	return nil
+}
+
+// matchesCurve ES256/ES384/ES512 分别要求 P-256/P-384/P-521 曲线
+func (m SigningMethod) matchesCurve(curve elliptic.Curve) bool {
+	switch m {
+	case SigningMethodES256:
+		return curve == elliptic.P256()
+	case SigningMethodES384:
+		return curve == elliptic.P384()
+	case SigningMethodES512:
+		return curve == elliptic.P521()
+	}
+	return false
+}
+
+// curveName ES256/ES384/ES512 要求的曲线名
+func (m SigningMethod) curveName() string {
+	switch m {
+	case SigningMethodES256:
+		return "P-256"
+	case SigningMethodES384:
+		return "P-384"
+	case SigningMethodES512:
+		return "P-521"
+	}
+	return ""
+}
+
+// keyTypeName 用于错误信息的密钥类型名
+func (m SigningMethod) keyTypeName() string {
+	switch m {
+	case SigningMethodEdDSA:
+		return "an Ed25519"
+	case SigningMethodRS256, SigningMethodRS384, SigningMethodRS512:
+		return "an RSA"
+	case SigningMethodES256, SigningMethodES384, SigningMethodES512:
+		return "an EC " + m.curveName()
+	}
+	return "a HMAC"
+}
@@ -1,6 +1,7 @@
 package jwt
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -39,11 +40,20 @@ MC4CAQAwBQYDK2VwBCIEIEFMEZrmlYzczXKFxIlNvNGR5JQvDhTkLevJYxwQd3ub
 			name: "HS256",
 			args: args{
 				signingMethod: SigningMethodHS256,
-				pkByte:        []byte(`random-secret`),
+				pkByte:        []byte(`random-secret-of-at-least-32-bytes`),
 			},
 			wantAlg: "HS256",
 			wantErr: false,
 		},
+		{
+			name: "HS256 weak secret",
+			args: args{
+				signingMethod: SigningMethodHS256,
+				pkByte:        []byte(`random-secret`),
+			},
+			wantAlg: "",
+			wantErr: true,
+		},
 		{
 			name: "RS256",
 			args: args{
@@ -82,7 +92,7 @@ jo53mJsCgYEA0mHg7CvHnjx4D6yNa08YBbflD+jtcPoFVI8jb1c16Uwy4+k38aHc
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := newSigner(tt.args.signingMethod, tt.args.pkByte)
+			got, err := newSigner(tt.args.signingMethod, tt.args.pkByte, false)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("newSigner() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -95,3 +105,28 @@ jo53mJsCgYEA0mHg7CvHnjx4D6yNa08YBbflD+jtcPoFVI8jb1c16Uwy4+k38aHc
 		})
 	}
 }
+
+func Test_newSigner_ExactAlgorithm(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	for m, k := range keys {
+		t.Run(string(m), func(t *testing.T) {
+			got, err := newSigner(m, k.privateKey, false)
+			if err != nil {
+				t.Fatalf("newSigner() error = %v", err)
+			}
+			if got.signingMethod.Alg() != string(m) {
+				t.Errorf("newSigner() got = %v, want %v", got.signingMethod.Alg(), m)
+			}
+		})
+	}
+}
+
+func Test_newSigner_CurveMismatch(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	if _, err := newSigner(SigningMethodES384, keys[SigningMethodES256].privateKey, false); !errors.Is(err, ErrInvalidPrivateKey) || !errors.Is(err, ErrKeyTypeMismatch) {
+		t.Errorf("newSigner() error = %v, want %v", err, ErrInvalidPrivateKey)
+	}
+	if _, err := newParser(SigningMethodES512, keys[SigningMethodES384].publicKey, false); !errors.Is(err, ErrInvalidPublicKey) || !errors.Is(err, ErrKeyTypeMismatch) {
+		t.Errorf("newParser() error = %v, want %v", err, ErrInvalidPublicKey)
+	}
+}
@@ -2,8 +2,11 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"time"
 
+	jose "github.com/go-jose/go-jose/v3"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -11,49 +14,179 @@ const (
 	defaultExpires = time.Hour * 24 * 7
 )
 
-// Option is a token generator option
-type Option func(*TokenGenerator)
+// Option is a token generator option shared by TokenGenerator and ClaimsGenerator[T].
+// Breaking change: it used to be func(*TokenGenerator), options can no longer be defined outside this package
+type Option func(*generator)
 
 // WithExpires set expires for token
 func WithExpires(expires time.Duration) Option {
-	return func(t *TokenGenerator) {
-		t.expires = expires
+	return func(g *generator) {
+		g.expires = expires
 	}
 }
 
-// TokenGenerator token generator
-type TokenGenerator struct {
+// WithKeyID set the kid header for token, ignored when generating with a KeyRing
+func WithKeyID(kid string) Option {
+	return func(g *generator) {
+		g.kid = kid
+	}
+}
+
+// WithIssuer set the iss claim for token when not set in claims
+func WithIssuer(iss string) Option {
+	return func(g *generator) {
+		g.issuer = iss
+	}
+}
+
+// WithAudience set the default aud claim for token when not set in claims
+func WithAudience(aud ...string) Option {
+	return func(g *generator) {
+		g.audience = aud
+	}
+}
+
+// WithSubjectFunc derive the sub claim from claims when not set,
+// e.g. for TokenGenerator:
+//
+//	jwt.WithSubjectFunc(func(c gojwt.Claims) string {
+//		return strconv.Itoa(int(c.(jwt.TokenClaims).UserID))
+//	})
+func WithSubjectFunc(fn func(claims jwt.Claims) string) Option {
+	return func(g *generator) {
+		g.subjectFunc = fn
+	}
+}
+
+// WithJTI set a random unique jti claim for token when not set in claims
+func WithJTI() Option {
+	return func(g *generator) {
+		g.jti = true
+	}
+}
+
+// generator TokenGenerator 与 ClaimsGenerator 共用的签发逻辑
+type generator struct {
 	signer  *Signer
+	keyRing *KeyRing
+	kid     string
 	expires time.Duration
+
+	issuer      string
+	audience    []string
+	subjectFunc func(claims jwt.Claims) string
+	jti         bool
+
+	clock        Clock
+	encrypter    jose.Encrypter
+	allowWeakKey bool
+	err          error // option 的错误，构造时或签发时返回
 }
 
-// NewTokenGenerator new token generator
-func NewTokenGenerator(signingMethod SigningMethod, privateKey []byte, opts ...Option) (*TokenGenerator, error) {
-	signer, err := newSigner(signingMethod, privateKey)
-	if err != nil {
-		return nil, err
-	}
-	g := &TokenGenerator{
+func newGenerator(signer *Signer, ring *KeyRing, opts []Option) *generator {
+	g := &generator{
 		signer:  signer,
+		keyRing: ring,
 		expires: defaultExpires,
+		clock:   systemClock{},
 	}
 
 	for _, opt := range opts {
 		opt(g)
 	}
 
-	return g, nil
+	return g
+}
+
+// fillRegisteredClaims 填充未设置的 exp/iat/nbf 以及配置了的 iss/aud/sub/jti，
+// claims 为包含 rc 的完整 claims，用于推导 sub
+func (g *generator) fillRegisteredClaims(rc *jwt.RegisteredClaims, claims jwt.Claims) error {
+	now := g.clock.Now()
+	if rc.ExpiresAt == nil {
+		rc.ExpiresAt = jwt.NewNumericDate(now.Add(g.expires))
+	}
+	if rc.IssuedAt == nil {
+		rc.IssuedAt = jwt.NewNumericDate(now)
+	}
+	if rc.NotBefore == nil {
+		rc.NotBefore = jwt.NewNumericDate(now)
+	}
+	if rc.Issuer == "" {
+		rc.Issuer = g.issuer
+	}
+	if len(rc.Audience) == 0 && len(g.audience) > 0 {
+		rc.Audience = append(jwt.ClaimStrings(nil), g.audience...)
+	}
+	if rc.Subject == "" && g.subjectFunc != nil {
+		rc.Subject = g.subjectFunc(claims)
+	}
+	if rc.ID == "" && g.jti {
+		id, err := randomToken(16)
+		if err != nil {
+			return err
+		}
+		rc.ID = id
+	}
+	return nil
+}
+
+// randomToken 生成 n 字节随机数的 base64url 编码
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// sign 使用当前签名密钥签发 token，设置了 kid 时写入头部，配置了加密时再加密为 JWE
+func (g *generator) sign(claims jwt.Claims) (string, error) {
+	if g.err != nil {
+		return "", g.err
+	}
+	signer, kid := g.signer, g.kid
+	if g.keyRing != nil {
+		key, err := g.keyRing.activeKey()
+		if err != nil {
+			return "", err
+		}
+		signer, kid = key.signer, key.id
+	}
+
+	token := jwt.NewWithClaims(signer.signingMethod, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(signer.privateKey)
+	if err != nil || g.encrypter == nil {
+		return signed, err
+	}
+	return encrypt(g.encrypter, signed)
+}
+
+// TokenGenerator token generator
+type TokenGenerator struct {
+	claims *ClaimsGenerator[TokenClaims]
+}
+
+// NewTokenGenerator new token generator
+func NewTokenGenerator(signingMethod SigningMethod, privateKey []byte, opts ...Option) (*TokenGenerator, error) {
+	g, err := NewClaimsGenerator[TokenClaims](signingMethod, privateKey, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenGenerator{claims: g}, nil
+}
+
+// NewTokenGeneratorWithKeyRing new token generator signing with the active key of ring,
+// the kid header is set to the key id
+func NewTokenGeneratorWithKeyRing(ring *KeyRing, opts ...Option) *TokenGenerator {
+	// TokenClaims 嵌入了 jwt.RegisteredClaims，不会出错；option 的错误在签发时返回
+	g, _ := newClaimsGenerator[TokenClaims](newGenerator(nil, ring, opts))
+	return &TokenGenerator{claims: g}
 }
 
 // Generate generate token
 func (g *TokenGenerator) Generate(info TokenInfo) (string, error) {
-	claims := TokenClaims{
-		info,
-		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(g.expires)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
-	}
-	return jwt.NewWithClaims(g.signer.signingMethod, claims).SignedString(g.signer.privateKey)
+	return g.claims.Generate(TokenClaims{TokenInfo: info})
 }
@@ -1,43 +1,224 @@
 package jwt
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var (
-	ErrInvalidToken = errors.New("invalid token")
-)
+// VerifierOption is a token verifier option
+type VerifierOption func(*verifier)
+
+// WithExpectedIssuer require the iss claim to be iss
+func WithExpectedIssuer(iss string) VerifierOption {
+	return func(v *verifier) {
+		v.issuer = iss
+	}
+}
+
+// WithExpectedAudience require the aud claim to contain aud
+func WithExpectedAudience(aud string) VerifierOption {
+	return func(v *verifier) {
+		v.audience = aud
+	}
+}
+
+// WithLeeway set the allowed clock skew when checking exp/nbf/iat
+func WithLeeway(leeway time.Duration) VerifierOption {
+	return func(v *verifier) {
+		v.leeway = leeway
+	}
+}
+
+// WithMaxAge reject tokens issued more than maxAge ago (plus leeway), the iat claim is required
+func WithMaxAge(maxAge time.Duration) VerifierOption {
+	return func(v *verifier) {
+		v.maxAge = maxAge
+	}
+}
+
+// verifier TokenVerifier 与 ClaimsVerifier 共用的验证逻辑
+type verifier struct {
+	parser  *Parser
+	keyRing *KeyRing
+	remote  *remoteKeySet
+
+	issuer   string
+	audience string
+	leeway   time.Duration
+	maxAge   time.Duration
+
+	revocationStore    RevocationStore
+	revocationCacheTTL time.Duration
+	revocationTimeout  time.Duration
+	revocation         *revocationChecker
+
+	clock         Clock
+	decrypter     *jweDecrypter
+	introspection *introspectionClient
+	allowWeakKey  bool
+	err           error // option 的错误，构造时或验证时返回
+}
+
+func newVerifier(parser *Parser, ring *KeyRing, remote *remoteKeySet, opts []VerifierOption) *verifier {
+	v := &verifier{
+		parser:  parser,
+		keyRing: ring,
+		remote:  remote,
+
+		revocationCacheTTL: defaultRevocationCacheTTL,
+		revocationTimeout:  defaultRevocationTimeout,
+		clock:              systemClock{},
+	}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	if v.revocationStore != nil {
+		v.revocation = newRevocationChecker(v.revocationStore, v.revocationCacheTTL, v.revocationTimeout, v.clock)
+	}
+
+	return v
+}
+
+// parse 验证 token 并将 claims 解析到 claims 中
+func (v *verifier) parse(tokenStr string, claims jwt.Claims) error {
+	if v.err != nil {
+		return v.err
+	}
+	if v.introspection != nil {
+		return v.parseIntrospected(tokenStr, claims)
+	}
+	if v.decrypter != nil {
+		signed, err := v.decrypter.decrypt(tokenStr)
+		if err != nil {
+			return err
+		}
+		tokenStr = signed
+	}
+
+	token, err := jwt.ParseWithClaims(tokenStr, claims, v.keyFunc, v.parserOptions()...)
+	if err != nil {
+		if v.parser != nil && token != nil && token.Header["alg"] != nil && token.Header["alg"] != string(v.parser.signingMethod) {
+			return newVerificationError(ReasonAlgorithmMismatch, err)
+		}
+		return newVerificationError(reasonOf(err), err)
+	}
+
+	if !token.Valid {
+		return newVerificationError(ReasonInvalid, nil)
+	}
+	return v.checkClaims(claims)
+}
+
+// checkClaims 签名与 exp/nbf 校验通过后，校验 iss/aud/iat 并查询吊销
+func (v *verifier) checkClaims(claims jwt.Claims) error {
+	if err := v.validateClaims(claims); err != nil {
+		return err
+	}
+	if v.revocation != nil {
+		return v.revocation.check(context.Background(), claims)
+	}
+	return nil
+}
+
+// validateClaims 校验 iss/aud/iat，签名与 exp/nbf 已由 jwt 库校验
+func (v *verifier) validateClaims(claims jwt.Claims) error {
+	if v.issuer != "" {
+		iss, err := claims.GetIssuer()
+		if err != nil || iss != v.issuer {
+			return newVerificationError(ReasonInvalidIssuer, err)
+		}
+	}
+
+	if v.audience != "" {
+		aud, err := claims.GetAudience()
+		if err != nil || !containsString(aud, v.audience) {
+			return newVerificationError(ReasonInvalidAudience, err)
+		}
+	}
+
+	if v.maxAge > 0 {
+		iat, err := claims.GetIssuedAt()
+		if err != nil || iat == nil || v.clock.Now().Sub(iat.Time) > v.maxAge+v.leeway {
+			return newVerificationError(ReasonTooOld, err)
+		}
+	}
+
+	return nil
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// keyFunc 返回验证 token 使用的公钥，使用密钥环时按 kid 选择并校验 alg
+func (v *verifier) keyFunc(t *jwt.Token) (interface{}, error) {
+	if v.keyRing == nil {
+		return v.parser.publicKey, nil
+	}
+
+	kid, _ := t.Header["kid"].(string)
+	key, err := v.keyRing.verificationKey(kid)
+	if errors.Is(err, ErrUnknownKeyID) && v.remote != nil && v.remote.refetch() {
+		// 远程密钥可能已轮换，重新拉取后再找一次
+		key, err = v.keyRing.verificationKey(kid)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if t.Method.Alg() != string(key.parser.signingMethod) {
+		return nil, ErrAlgorithmMismatch
+	}
+	return key.parser.publicKey, nil
+}
+
+func (v *verifier) parserOptions() []jwt.ParserOption {
+	opts := []jwt.ParserOption{jwt.WithLeeway(v.leeway), jwt.WithTimeFunc(v.clock.Now)}
+	if v.maxAge > 0 {
+		// 拒绝 iat 在未来的 token
+		opts = append(opts, jwt.WithIssuedAt())
+	}
+	if v.keyRing == nil {
+		// 使用密钥环时每把密钥的 alg 在 keyFunc 中校验
+		opts = append(opts, jwt.WithValidMethods([]string{string(v.parser.signingMethod)}))
+	}
+	return opts
+}
 
 // TokenVerifier token verifier
 type TokenVerifier struct {
-	parser *Parser
+	claims *ClaimsVerifier[TokenClaims]
 }
 
 // NewTokenVerifier new a token verifier
-func NewTokenVerifier(signingMethod SigningMethod, publicKey []byte) (*TokenVerifier, error) {
-	parser, err := newParser(signingMethod, publicKey)
+func NewTokenVerifier(signingMethod SigningMethod, publicKey []byte, opts ...VerifierOption) (*TokenVerifier, error) {
+	v, err := NewClaimsVerifier[TokenClaims](signingMethod, publicKey, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return &TokenVerifier{
-		parser: parser,
-	}, nil
+	return &TokenVerifier{claims: v}, nil
+}
+
+// NewTokenVerifierWithKeyRing new a token verifier selecting the key by the kid header,
+// only active and verify-only keys of ring are accepted
+func NewTokenVerifierWithKeyRing(ring *KeyRing, opts ...VerifierOption) *TokenVerifier {
+	return &TokenVerifier{claims: NewClaimsVerifierWithKeyRing[TokenClaims](ring, opts...)}
 }
 
 // Verify verify token
 func (v *TokenVerifier) Verify(tokenStr string) (*TokenInfo, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &TokenClaims{}, func(t *jwt.Token) (interface{}, error) {
-		return v.parser.publicKey, nil
-	})
+	c, err := v.claims.Verify(tokenStr)
 	if err != nil {
-		return nil, ErrInvalidToken
-	}
-
-	if c, ok := token.Claims.(*TokenClaims); ok && token.Valid {
-		return &c.TokenInfo, nil
+		return nil, err
 	}
-
-	return nil, ErrInvalidToken
+	return &c.TokenInfo, nil
 }
@@ -4,8 +4,20 @@ import "github.com/golang-jwt/jwt/v5"
 
 // TokenInfo token info
 type TokenInfo struct {
-	UserID uint `json:"user_id,omitempty"`
-	RoleID uint `json:"role_id,omitempty"`
+	UserID uint     `json:"user_id,omitempty"`
+	RoleID uint     `json:"role_id,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+	Roles  []string `json:"roles,omitempty"`
+}
+
+// GetScopes implements ScopedClaims
+func (i TokenInfo) GetScopes() []string {
+	return i.Scopes
+}
+
+// GetRoles implements ScopedClaims
+func (i TokenInfo) GetRoles() []string {
+	return i.Roles
 }
 
 // TokenClaims token claims
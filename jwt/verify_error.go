@@ -0,0 +1,125 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrAlgorithmMismatch token 头部的 alg 与 verifier 配置的签名方法不一致
+	ErrAlgorithmMismatch = fmt.Errorf("%w: signing algorithm mismatch", ErrInvalidToken)
+	ErrTokenMalformed    = fmt.Errorf("%w: token is malformed", ErrInvalidToken)
+	ErrSignatureInvalid  = fmt.Errorf("%w: signature is invalid", ErrInvalidToken)
+	ErrTokenExpired      = fmt.Errorf("%w: token is expired", ErrInvalidToken)
+	ErrTokenNotValidYet  = fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	ErrInvalidIssuer     = fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	ErrInvalidAudience   = fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	// ErrTokenTooOld token 的 iat 早于 max age 允许的时间，或缺少 iat
+	ErrTokenTooOld = fmt.Errorf("%w: token is too old", ErrInvalidToken)
+	// ErrInvalidClaims claims 自定义的 Validate 校验失败
+	ErrInvalidClaims = fmt.Errorf("%w: invalid claims", ErrInvalidToken)
+)
+
+// Reason is the reason code of a VerificationError
+type Reason string
+
+const (
+	ReasonInvalid           Reason = "invalid"
+	ReasonMalformed         Reason = "malformed"
+	ReasonSignatureInvalid  Reason = "signature_invalid"
+	ReasonAlgorithmMismatch Reason = "algorithm_mismatch"
+	ReasonUnknownKeyID      Reason = "unknown_kid"
+	ReasonExpired           Reason = "expired"
+	ReasonNotValidYet       Reason = "not_valid_yet"
+	ReasonInvalidIssuer     Reason = "invalid_issuer"
+	ReasonInvalidAudience   Reason = "invalid_audience"
+	ReasonTooOld            Reason = "too_old"
+	ReasonInvalidClaims     Reason = "invalid_claims"
+	ReasonRevoked           Reason = "revoked"
+	ReasonDecryptionFailed  Reason = "decryption_failed"
+	ReasonInactive          Reason = "inactive"
+)
+
+// reasonErrors 每个 reason 对应的哨兵错误，均包装了 ErrInvalidToken
+var reasonErrors = map[Reason]error{
+	ReasonInvalid:           ErrInvalidToken,
+	ReasonMalformed:         ErrTokenMalformed,
+	ReasonSignatureInvalid:  ErrSignatureInvalid,
+	ReasonAlgorithmMismatch: ErrAlgorithmMismatch,
+	ReasonUnknownKeyID:      ErrUnknownKeyID,
+	ReasonExpired:           ErrTokenExpired,
+	ReasonNotValidYet:       ErrTokenNotValidYet,
+	ReasonInvalidIssuer:     ErrInvalidIssuer,
+	ReasonInvalidAudience:   ErrInvalidAudience,
+	ReasonTooOld:            ErrTokenTooOld,
+	ReasonInvalidClaims:     ErrInvalidClaims,
+	ReasonRevoked:           ErrTokenRevoked,
+	ReasonDecryptionFailed:  ErrDecryptionFailed,
+	ReasonInactive:          ErrTokenInactive,
+}
+
+// VerificationError is returned by Verify when a token is rejected.
+// errors.Is matches ErrInvalidToken, the sentinel error of Reason (e.g. ErrTokenExpired)
+// and the underlying golang-jwt error (e.g. jwt.ErrTokenExpired)
+type VerificationError struct {
+	Reason Reason
+	Err    error // golang-jwt 返回的原始错误，可能为 nil
+}
+
+func newVerificationError(reason Reason, err error) *VerificationError {
+	return &VerificationError{Reason: reason, Err: err}
+}
+
+func (e *VerificationError) Error() string {
+	if e.Err == nil {
+		return e.sentinel().Error()
+	}
+	return e.sentinel().Error() + ": " + e.Err.Error()
+}
+
+func (e *VerificationError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel()}
+	}
+	return []error{e.sentinel(), e.Err}
+}
+
+func (e *VerificationError) sentinel() error {
+	if err, ok := reasonErrors[e.Reason]; ok {
+		return err
+	}
+	return ErrInvalidToken
+}
+
+// ReasonOf return the reason code of a verification error, empty when err is not a VerificationError
+func ReasonOf(err error) Reason {
+	var ve *VerificationError
+	if errors.As(err, &ve) {
+		return ve.Reason
+	}
+	return ""
+}
+
+// reasonOf 将 golang-jwt 的解析错误映射为 reason
+func reasonOf(err error) Reason {
+	switch {
+	case errors.Is(err, ErrUnknownKeyID):
+		return ReasonUnknownKeyID
+	case errors.Is(err, ErrAlgorithmMismatch):
+		return ReasonAlgorithmMismatch
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return ReasonMalformed
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return ReasonSignatureInvalid
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return ReasonExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return ReasonNotValidYet
+	case errors.Is(err, jwt.ErrTokenInvalidClaims):
+		return ReasonInvalidClaims
+	}
+	return ReasonInvalid
+}
@@ -0,0 +1,81 @@
+package jwt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestVerificationError_Reason(t *testing.T) {
+	keys := newTestKeyPairs(t)
+	g, err := NewClaimsGenerator[TokenClaims](SigningMethodES256, keys[SigningMethodES256].privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewTokenVerifier(SigningMethodES256, keys[SigningMethodES256].publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid, err := g.Generate(TokenClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := g.Generate(TokenClaims{RegisteredClaims: jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	notYet, err := g.Generate(TokenClaims{RegisteredClaims: jwt.RegisteredClaims{
+		NotBefore: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(valid, ".")
+	hs, err := jwt.NewWithClaims(jwt.SigningMethodHS256, TokenClaims{}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		tokenStr   string
+		wantReason Reason
+		wantErrs   []error
+	}{
+		{name: "malformed", tokenStr: "not.a.token", wantReason: ReasonMalformed, wantErrs: []error{ErrTokenMalformed, jwt.ErrTokenMalformed}},
+		{name: "bad signature", tokenStr: parts[0] + "." + parts[1] + "." + strings.Split(expired, ".")[2], wantReason: ReasonSignatureInvalid, wantErrs: []error{ErrSignatureInvalid, jwt.ErrTokenSignatureInvalid}},
+		{name: "expired", tokenStr: expired, wantReason: ReasonExpired, wantErrs: []error{ErrTokenExpired, jwt.ErrTokenExpired}},
+		{name: "not valid yet", tokenStr: notYet, wantReason: ReasonNotValidYet, wantErrs: []error{ErrTokenNotValidYet, jwt.ErrTokenNotValidYet}},
+		{name: "algorithm mismatch", tokenStr: hs, wantReason: ReasonAlgorithmMismatch, wantErrs: []error{ErrAlgorithmMismatch}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Verify(tt.tokenStr)
+			var ve *VerificationError
+			if !errors.As(err, &ve) {
+				t.Fatalf("Verify() error = %v, want *VerificationError", err)
+			}
+			if ve.Reason != tt.wantReason || ReasonOf(err) != tt.wantReason {
+				t.Errorf("Reason = %q, want %q", ve.Reason, tt.wantReason)
+			}
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Verify() error = %v, want wrapping %v", err, ErrInvalidToken)
+			}
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("Verify() error = %v, want wrapping %v", err, want)
+				}
+			}
+		})
+	}
+
+	if _, err := v.Verify(valid); err != nil || ReasonOf(err) != "" {
+		t.Errorf("Verify() error = %v, reason = %q", err, ReasonOf(err))
+	}
+}
@@ -0,0 +1,88 @@
+package lock
+
+import (
+	"context"
+	"time"
+)
+
+// 到达屏障的 Lua 脚本：计数加一，满员时推进代数并广播
+// KEYS[1] 计数 key，KEYS[2] 代数 key；ARGV[1] parties，ARGV[2] ttl(ms)，ARGV[3] 通知 channel
+const barrierArriveScript = `
+local gen = tonumber(redis.call("get",KEYS[2]) or "0")
+local n = redis.call("incr",KEYS[1])
+if n >= tonumber(ARGV[1]) then
+    redis.call("del",KEYS[1])
+    redis.call("set",KEYS[2],gen+1,"px",ARGV[2])
+    redis.call("publish",ARGV[3],gen+1)
+    return {1,gen}
+end
+redis.call("pexpire",KEYS[1],ARGV[2])
+redis.call("pexpire",KEYS[2],ARGV[2])
+return {0,gen}`
+
+// 检查屏障状态：1 已放行，0 等待中，-1 计数已过期（屏障被破坏）
+const barrierCheckScript = `
+local gen = tonumber(redis.call("get",KEYS[2]) or "0")
+if gen > tonumber(ARGV[1]) then
+    return 1
+end
+if redis.call("exists",KEYS[1]) == 0 then
+    return -1
+end
+return 0`
+
+// 离开屏障：仅当仍处于同一代时撤回自己的计数
+const barrierLeaveScript = `
+local gen = tonumber(redis.call("get",KEYS[2]) or "0")
+if gen ~= tonumber(ARGV[1]) then
+    return 0
+end
+local n = tonumber(redis.call("get",KEYS[1]) or "0")
+if n <= 1 then
+    redis.call("del",KEYS[1])
+else
+    redis.call("decr",KEYS[1])
+end
+return 1`
+
+// Barrier 阻塞直到 parties 个参与者都到达屏障。
+// 超过 ttl 没有新的参与者到达时计数会被清理，仍在等待的参与者返回 ErrBarrierBroken；
+// ctx 取消时会撤回自己的计数，不影响其他参与者重新凑齐
+func (c *redisCoordinator) Barrier(ctx context.Context, name string, parties int) error {
+	if parties <= 0 {
+		return ErrInvalidParties
+	}
+
+	keys := []string{
+		c.buildFullKey("barrier", name, "count"),
+		c.buildFullKey("barrier", name, "gen"),
+	}
+	channel := c.buildFullKey("barrier", name, "notify")
+
+	res, err := c.rd.Eval(ctx, barrierArriveScript, keys, parties, c.ttl.Milliseconds(), channel).Slice()
+	if err != nil {
+		return err
+	}
+	if res[0].(int64) == 1 {
+		return nil // 最后一个到达者，直接放行
+	}
+	gen := res[1].(int64)
+
+	err = c.waitFor(ctx, channel, func(ctx context.Context) (bool, error) {
+		state, err := c.rd.Eval(ctx, barrierCheckScript, keys, gen).Int64()
+		if err != nil {
+			return false, err
+		}
+		if state < 0 {
+			return false, ErrBarrierBroken
+		}
+		return state == 1, nil
+	})
+	if err != nil && ctx.Err() != nil {
+		// ctx 已取消，使用独立的 context 撤回计数
+		leaveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Second)
+		defer cancel()
+		c.rd.Eval(leaveCtx, barrierLeaveScript, keys, gen)
+	}
+	return err
+}
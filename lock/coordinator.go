@@ -0,0 +1,111 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrBarrierBroken  = errors.New("barrier broken")
+	ErrLatchExpired   = errors.New("latch expired")
+	ErrInvalidParties = errors.New("parties must be positive")
+	ErrInvalidCount   = errors.New("count must be positive")
+)
+
+var (
+	defaultCoordinatorTTL = time.Minute
+	defaultPollInterval   = 200 * time.Millisecond
+)
+
+// Coordinator 分布式协调原语（屏障、倒计时门闩）
+type Coordinator interface {
+	// Barrier 阻塞直到 parties 个参与者都到达名为 name 的屏障
+	Barrier(ctx context.Context, name string, parties int) error
+	// CountDownLatch 创建（或加入已存在的）计数为 count 的倒计时门闩
+	CountDownLatch(ctx context.Context, name string, count int) (CountDownLatch, error)
+}
+
+type redisCoordinator struct {
+	rd           *redis.Client
+	keyPrefix    string
+	ttl          time.Duration // 无进展时自动清理的时间，防止崩溃的参与者永久卡住整组
+	pollInterval time.Duration // pub/sub 消息丢失时的兜底轮询间隔
+}
+
+type CoordinatorOption func(c *redisCoordinator)
+
+func WithCoordinatorKeyPrefix(keyPrefix string) CoordinatorOption {
+	return func(c *redisCoordinator) {
+		c.keyPrefix = keyPrefix
+	}
+}
+
+func WithCoordinatorTTL(ttl time.Duration) CoordinatorOption {
+	return func(c *redisCoordinator) {
+		c.ttl = ttl
+	}
+}
+
+func WithPollInterval(interval time.Duration) CoordinatorOption {
+	return func(c *redisCoordinator) {
+		c.pollInterval = interval
+	}
+}
+
+func NewRedisCoordinator(rd *redis.Client, opts ...CoordinatorOption) Coordinator {
+	c := &redisCoordinator{
+		rd:           rd,
+		keyPrefix:    "",
+		ttl:          defaultCoordinatorTTL,
+		pollInterval: defaultPollInterval,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// 构建完整的key，使用 hash tag 保证同一原语的多个 key 落在同一个 slot
+func (c *redisCoordinator) buildFullKey(kind, name, suffix string) string {
+	if c.keyPrefix == "" {
+		return fmt.Sprintf("%s:{%s}:%s", kind, name, suffix)
+	}
+	return fmt.Sprintf("%s:%s:{%s}:%s", c.keyPrefix, kind, name, suffix)
+}
+
+// waitFor 订阅 channel 并等待 check 返回 done，pub/sub 通知只用于唤醒，
+// 结果始终以 check 读到的 Redis 状态为准
+func (c *redisCoordinator) waitFor(ctx context.Context, channel string, check func(ctx context.Context) (bool, error)) error {
+	pubsub := c.rd.Subscribe(ctx, channel)
+	defer pubsub.Close()
+
+	// 等待订阅确认，避免在订阅生效前错过通知
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return err
+	}
+	msgCh := pubsub.Channel()
+
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		done, err := check(ctx)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-msgCh:
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rd := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rd.Close() })
+	return mr, rd
+}
+
+func newTestCoordinator(t *testing.T) (*miniredis.Miniredis, Coordinator) {
+	t.Helper()
+	mr, rd := newTestRedis(t)
+	return mr, NewRedisCoordinator(rd, WithCoordinatorKeyPrefix("test"), WithPollInterval(10*time.Millisecond))
+}
+
+// arriveAll parties 个参与者同时到达屏障，返回每个参与者的结果
+func arriveAll(ctx context.Context, c Coordinator, name string, parties int) []error {
+	errs := make([]error, parties)
+	var wg sync.WaitGroup
+	for i := 0; i < parties; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = c.Barrier(ctx, name, parties)
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
+
+func TestBarrier(t *testing.T) {
+	mr, c := newTestCoordinator(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 同一个屏障连续使用多代
+	for gen := 0; gen < 3; gen++ {
+		for i, err := range arriveAll(ctx, c, "b", 4) {
+			if err != nil {
+				t.Fatalf("generation %d party %d: %v", gen, i, err)
+			}
+		}
+	}
+	if got := mr.Exists("test:barrier:{b}:count"); got {
+		t.Error("count key left after the barrier tripped")
+	}
+
+	// 人数不足时等待，ctx 结束后撤回自己的计数
+	waitCtx, waitCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer waitCancel()
+	if err := c.Barrier(waitCtx, "b", 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Barrier() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if mr.Exists("test:barrier:{b}:count") {
+		t.Error("count not withdrawn after ctx ended")
+	}
+
+	// 撤回后下一代仍需凑齐人数
+	for i, err := range arriveAll(ctx, c, "b", 2) {
+		if err != nil {
+			t.Fatalf("party %d after withdrawal: %v", i, err)
+		}
+	}
+
+	if err := c.Barrier(ctx, "b", 0); !errors.Is(err, ErrInvalidParties) {
+		t.Errorf("Barrier(0) error = %v, want %v", err, ErrInvalidParties)
+	}
+}
+
+func TestBarrier_Broken(t *testing.T) {
+	mr, c := newTestCoordinator(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- c.Barrier(ctx, "b", 2) }()
+
+	// 等待第一个参与者到达后让计数过期
+	for !mr.Exists("test:barrier:{b}:count") {
+		time.Sleep(time.Millisecond)
+	}
+	mr.FastForward(defaultCoordinatorTTL)
+	if err := <-errCh; !errors.Is(err, ErrBarrierBroken) {
+		t.Errorf("Barrier() error = %v, want %v", err, ErrBarrierBroken)
+	}
+}
+
+func TestCountDownLatch(t *testing.T) {
+	_, c := newTestCoordinator(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	latch, err := c.CountDownLatch(ctx, "l", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 同名门闩加入时不重置计数
+	joined, err := c.CountDownLatch(ctx, "l", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- joined.Await(ctx) }()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case err := <-done:
+			t.Fatalf("Await() returned %v before the count reached 0", err)
+		default:
+		}
+		if err := latch.CountDown(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Await() error = %v", err)
+	}
+	if n, err := latch.Count(ctx); err != nil || n != 0 {
+		t.Errorf("Count() = %d, %v, want 0", n, err)
+	}
+
+	// 已归零的门闩立即返回，多余的 CountDown 不会变成负数
+	if err := latch.CountDown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := latch.Await(ctx); err != nil {
+		t.Errorf("Await() on a completed latch error = %v", err)
+	}
+
+	if _, err := c.CountDownLatch(ctx, "l", 0); !errors.Is(err, ErrInvalidCount) {
+		t.Errorf("CountDownLatch(0) error = %v, want %v", err, ErrInvalidCount)
+	}
+}
+
+func TestCountDownLatch_Expired(t *testing.T) {
+	mr, c := newTestCoordinator(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	latch, err := c.CountDownLatch(ctx, "l", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr.FastForward(defaultCoordinatorTTL)
+
+	if err := latch.CountDown(ctx); !errors.Is(err, ErrLatchExpired) {
+		t.Errorf("CountDown() error = %v, want %v", err, ErrLatchExpired)
+	}
+	if err := latch.Await(ctx); !errors.Is(err, ErrLatchExpired) {
+		t.Errorf("Await() error = %v, want %v", err, ErrLatchExpired)
+	}
+}
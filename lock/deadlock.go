@@ -0,0 +1,107 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDeadlock = errors.New("deadlock detected")
+)
+
+// 锁的值中 owner 与随机串的分隔符
+const ownerSeparator = "#"
+
+// 死锁检测的 Lua 脚本：记录 waiter -> holder 的等待边，并沿等待链查找回到 waiter 的环。
+// 每个 owner 同一时间最多等待一把锁，等待图中每个节点只有一条出边，顺着链走即可。
+// 检测在脚本内原子完成，同一个环只有最后闭合它的等待者会收到死锁。
+// KEYS[1] 锁 key；ARGV[1] waiter，ARGV[2] 等待边 key 前缀，ARGV[3] 等待边过期时间(ms)，ARGV[4] 最大深度
+// 注意：等待边 key 在脚本内拼接，仅支持单机 Redis 或所有 key 在同一 slot 的部署
+const deadlockDetectScript = `
+local v = redis.call("get",KEYS[1])
+if not v then
+    return 0
+end
+local holder = string.match(v,"^(.*)` + ownerSeparator + `[^` + ownerSeparator + `]*$")
+if not holder or holder == "" then
+    return 0
+end
+if holder == ARGV[1] then
+    return 1
+end
+redis.call("set",ARGV[2]..ARGV[1],holder,"px",ARGV[3])
+local cur = holder
+for i = 1, tonumber(ARGV[4]) do
+    local nxt = redis.call("get",ARGV[2]..cur)
+    if not nxt then
+        return 0
+    end
+    if nxt == ARGV[1] then
+        redis.call("del",ARGV[2]..ARGV[1])
+        return 1
+    end
+    cur = nxt
+end
+return 0`
+
+// 等待链的最大追踪深度
+const maxWaitForDepth = 64
+
+type ownerCtxKey struct{}
+
+// ContextWithOwner 设置持锁者标识，同一个 owner（如一次请求、一个任务）获取的多把锁
+// 在死锁检测中被视为同一个参与者
+func ContextWithOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, ownerCtxKey{}, owner)
+}
+
+// OwnerFromContext 获取持锁者标识
+func OwnerFromContext(ctx context.Context) (string, bool) {
+	owner, ok := ctx.Value(ownerCtxKey{}).(string)
+	return owner, ok && owner != ""
+}
+
+// WithDeadlockDetection 开启死锁检测：等待锁时在 Redis 中记录等待边，
+// 发现环时让闭合环的等待者返回 ErrDeadlock，而不是所有人都等到 ErrLockTimeout。
+// 只对通过 ContextWithOwner 设置了 owner 的调用生效
+func WithDeadlockDetection() RedisLockerOption {
+	return func(l *redisLocker) {
+		l.detectDeadlock = true
+	}
+}
+
+// newLockValue 生成唯一的锁标识，带 owner 时格式为 owner#uuid
+func newLockValue(owner string) string {
+	if owner == "" {
+		return uuid.New().String()
+	}
+	return owner + ownerSeparator + uuid.New().String()
+}
+
+func (l *redisLocker) waitForKeyPrefix() string {
+	return l.buildFullKey("deadlock:waitfor") + ":"
+}
+
+// checkDeadlock 记录 owner 正在等待 fullKey 的持有者，存在等待环时返回 ErrDeadlock。
+// 等待边最多存活本次调用的等待时间 wait，进程崩溃未清理时也不会长期残留或提前消失
+func (l *redisLocker) checkDeadlock(ctx context.Context, fullKey, owner string, wait time.Duration) error {
+	// PX 至少为 1ms，否则 Redis 拒绝执行
+	px := max(wait.Milliseconds(), 1)
+	res, err := l.rd.Eval(ctx, deadlockDetectScript, []string{fullKey},
+		owner, l.waitForKeyPrefix(), px, maxWaitForDepth).Int64()
+	if err != nil {
+		return err
+	}
+	if res == 1 {
+		return ErrDeadlock
+	}
+	return nil
+}
+
+// clearWaitFor 停止等待后删除等待边
+func (l *redisLocker) clearWaitFor(ctx context.Context, owner string) {
+	l.rd.Del(context.WithoutCancel(ctx), l.waitForKeyPrefix()+owner)
+}
@@ -0,0 +1,90 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRedisLocker_Deadlock(t *testing.T) {
+	mr, rd := newTestRedis(t)
+	l := NewRedisLocker(rd, WithKeyPrefix("test"), WithTTL(time.Minute), WithDeadlockDetection())
+	ctxA := ContextWithOwner(context.Background(), "A")
+	ctxB := ContextWithOwner(context.Background(), "B")
+	retry := WithRetryPolicy(FixedInterval(5 * time.Millisecond))
+
+	unlockA, err := l.Lock(ctxA, "k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlockB, err := l.Lock(ctxB, "k2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// B 等待 A 持有的 k1
+	bDone := make(chan error, 1)
+	go func() {
+		u, err := l.LockWithTimeout(ctxB, "k1", 2*time.Second, retry)
+		if err == nil {
+			err = u(ctxB)
+		}
+		bDone <- err
+	}()
+	for !mr.Exists("test:deadlock:waitfor:B") {
+		time.Sleep(time.Millisecond)
+	}
+	// 等待边按本次等待时间过期，而不是锁的 TTL
+	if ttl := mr.TTL("test:deadlock:waitfor:B"); ttl <= 0 || ttl > 2*time.Second {
+		t.Errorf("wait-for edge TTL = %v, want within the 2s wait timeout", ttl)
+	}
+
+	// A 再等待 B 持有的 k2，形成 A→B→A 的环
+	start := time.Now()
+	if _, err := l.LockWithTimeout(ctxA, "k2", 2*time.Second, retry); !errors.Is(err, ErrDeadlock) {
+		t.Fatalf("LockWithTimeout() error = %v, want %v", err, ErrDeadlock)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("deadlock reported after %v, want before the wait timeout", elapsed)
+	}
+	if mr.Exists("test:deadlock:waitfor:A") {
+		t.Error("wait-for edge of A left after ErrDeadlock")
+	}
+
+	// A 放弃 k1 后 B 获得锁
+	if err := unlockA(ctxA); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-bDone; err != nil {
+		t.Fatalf("B error = %v", err)
+	}
+	if err := unlockB(ctxB); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists("test:deadlock:waitfor:B") {
+		t.Error("wait-for edge of B left after B stopped waiting")
+	}
+}
+
+func TestRedisLocker_DeadlockShortWait(t *testing.T) {
+	_, rd := newTestRedis(t)
+	l := NewRedisLocker(rd, WithKeyPrefix("test"), WithDeadlockDetection())
+	ctxA := ContextWithOwner(context.Background(), "A")
+	ctxB := ContextWithOwner(context.Background(), "B")
+
+	unlock, err := l.Lock(ctxA, "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock(ctxA)
+
+	// 不足 1ms 的等待时间仍然正常检测并超时，而不是返回 Redis 的错误
+	if _, err := l.LockWithTimeout(ctxB, "k", 500*time.Microsecond); !errors.Is(err, ErrLockTimeout) {
+		t.Errorf("LockWithTimeout(500µs) error = %v, want %v", err, ErrLockTimeout)
+	}
+	// 同一个 owner 重复获取也能检测到
+	if _, err := l.LockWithTimeout(ctxA, "k", 500*time.Microsecond); !errors.Is(err, ErrDeadlock) {
+		t.Errorf("LockWithTimeout(500µs) by the holder error = %v, want %v", err, ErrDeadlock)
+	}
+}
@@ -0,0 +1,223 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrBackendUnavailable = errors.New("lock backend unavailable")
+)
+
+var (
+	defaultFailureThreshold = 3
+	defaultCooldown         = 5 * time.Second
+	defaultProbeTimeout     = time.Second
+)
+
+// FallbackPolicy Redis 不可用时的处理策略
+type FallbackPolicy int
+
+const (
+	// FailClosed 直接返回 ErrBackendUnavailable
+	FailClosed FallbackPolicy = iota
+	// FailOpen 降级为进程内的锁，只保证当前进程内互斥，适用于尽力而为的操作
+	FailOpen
+)
+
+type breakerState int
+
+const (
+	breakerClosed   breakerState = iota // 正常使用 Redis
+	breakerOpen                         // Redis 不可用，走降级策略
+	breakerHalfOpen                     // 冷却结束，正在探测 Redis
+)
+
+type fallbackLocker struct {
+	primary Locker
+	local   Locker
+	policy  FallbackPolicy
+
+	healthCheck      func(ctx context.Context) error
+	failureThreshold int           // 连续失败多少次后熔断
+	cooldown         time.Duration // 熔断后多久再探测 Redis
+	now              func() time.Time
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+type FallbackLockerOption func(f *fallbackLocker)
+
+func WithFallbackPolicy(policy FallbackPolicy) FallbackLockerOption {
+	return func(f *fallbackLocker) {
+		f.policy = policy
+	}
+}
+
+func WithFailureThreshold(n int) FallbackLockerOption {
+	return func(f *fallbackLocker) {
+		f.failureThreshold = n
+	}
+}
+
+func WithCooldown(cooldown time.Duration) FallbackLockerOption {
+	return func(f *fallbackLocker) {
+		f.cooldown = cooldown
+	}
+}
+
+// WithHealthCheck 自定义探测方法，默认使用 PING
+func WithHealthCheck(check func(ctx context.Context) error) FallbackLockerOption {
+	return func(f *fallbackLocker) {
+		f.healthCheck = check
+	}
+}
+
+// NewFallbackLocker 包装 primary，Redis 连续出错时熔断并按 policy 降级，
+// 冷却结束后探测 Redis 健康状态，恢复后自动切回
+func NewFallbackLocker(rd *redis.Client, primary Locker, opts ...FallbackLockerOption) Locker {
+	f := &fallbackLocker{
+		primary:          primary,
+		local:            NewLocalLocker(),
+		policy:           FailClosed,
+		failureThreshold: defaultFailureThreshold,
+		cooldown:         defaultCooldown,
+		now:              time.Now,
+		healthCheck: func(ctx context.Context) error {
+			return rd.Ping(ctx).Err()
+		},
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// Lock 获取锁(阻塞式，使用默认超时时间)
+func (f *fallbackLocker) Lock(ctx context.Context, key string, opts ...LockOption) (UnLockFunc, error) {
+	return f.do(ctx, func(l Locker) (UnLockFunc, error) {
+		return l.Lock(ctx, key, opts...)
+	})
+}
+
+// TryLock 尝试获取锁(非阻塞)
+func (f *fallbackLocker) TryLock(ctx context.Context, key string, opts ...LockOption) (UnLockFunc, error) {
+	return f.do(ctx, func(l Locker) (UnLockFunc, error) {
+		return l.TryLock(ctx, key, opts...)
+	})
+}
+
+// LockWithTimeout 在超时时间内等待获取锁（阻塞式）
+func (f *fallbackLocker) LockWithTimeout(ctx context.Context, key string, timeout time.Duration, opts ...LockOption) (UnLockFunc, error) {
+	return f.do(ctx, func(l Locker) (UnLockFunc, error) {
+		return l.LockWithTimeout(ctx, key, timeout, opts...)
+	})
+}
+
+// Close 关闭 primary（实现了 Closer 时）和降级用的进程内锁
+func (f *fallbackLocker) Close(ctx context.Context) error {
+	return errors.Join(closeLocker(ctx, f.primary), closeLocker(ctx, f.local))
+}
+
+func (f *fallbackLocker) do(ctx context.Context, acquire func(l Locker) (UnLockFunc, error)) (UnLockFunc, error) {
+	if !f.allow(ctx) {
+		return f.fallback(acquire, nil)
+	}
+
+	unlock, err := acquire(f.primary)
+	if !isBackendError(err) {
+		f.onSuccess()
+		return unlock, err
+	}
+
+	f.onFailure()
+	return f.fallback(acquire, err)
+}
+
+func (f *fallbackLocker) fallback(acquire func(l Locker) (UnLockFunc, error), cause error) (UnLockFunc, error) {
+	if f.policy == FailOpen {
+		return acquire(f.local)
+	}
+	if cause != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBackendUnavailable, cause)
+	}
+	return nil, ErrBackendUnavailable
+}
+
+// allow 判断本次请求是否可以访问 Redis，熔断冷却结束后由一个请求负责探测
+func (f *fallbackLocker) allow(ctx context.Context) bool {
+	f.mu.Lock()
+	switch f.state {
+	case breakerClosed:
+		f.mu.Unlock()
+		return true
+	case breakerOpen:
+		if f.now().Sub(f.openedAt) < f.cooldown {
+			f.mu.Unlock()
+			return false
+		}
+		f.state = breakerHalfOpen
+		f.mu.Unlock()
+	default:
+		// 其他请求正在探测
+		f.mu.Unlock()
+		return false
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, defaultProbeTimeout)
+	err := f.healthCheck(probeCtx)
+	cancel()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err != nil {
+		f.state = breakerOpen
+		f.openedAt = f.now()
+		return false
+	}
+	f.state = breakerClosed
+	f.failures = 0
+	return true
+}
+
+func (f *fallbackLocker) onSuccess() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = 0
+}
+
+func (f *fallbackLocker) onFailure() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures++
+	if f.state == breakerClosed && f.failures >= f.failureThreshold {
+		f.state = breakerOpen
+		f.openedAt = f.now()
+	}
+}
+
+// isBackendError 判断是否为 Redis 自身的故障，锁被占用、超时等业务结果不计入熔断
+func isBackendError(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch {
+	case errors.Is(err, ErrLockNotAcquired),
+		errors.Is(err, ErrLockTimeout),
+		errors.Is(err, ErrNotLockOwner),
+		errors.Is(err, ErrLockerClosed),
+		errors.Is(err, ErrDeadlock),
+		errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded):
+		return false
+	}
+	return true
+}
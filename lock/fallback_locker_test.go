@@ -0,0 +1,299 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errRedisDown = errors.New("dial tcp: connection refused")
+
+// stubLocker 按需失败的 primary
+type stubLocker struct {
+	mu    sync.Mutex
+	err   error
+	calls int
+}
+
+func (s *stubLocker) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *stubLocker) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func (s *stubLocker) acquire() (UnLockFunc, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return func(context.Context) error { return nil }, nil
+}
+
+func (s *stubLocker) Lock(context.Context, string, ...LockOption) (UnLockFunc, error) {
+	return s.acquire()
+}
+
+func (s *stubLocker) TryLock(context.Context, string, ...LockOption) (UnLockFunc, error) {
+	return s.acquire()
+}
+
+func (s *stubLocker) LockWithTimeout(context.Context, string, time.Duration, ...LockOption) (UnLockFunc, error) {
+	return s.acquire()
+}
+
+// testBreaker 熔断测试环境，时间由 now 控制
+type testBreaker struct {
+	*fallbackLocker
+	primary *stubLocker
+	probes  atomic.Int32
+	probe   func(ctx context.Context) error // 探测结果，默认成功
+	now     time.Time
+}
+
+func newTestBreaker(t *testing.T, opts ...FallbackLockerOption) *testBreaker {
+	t.Helper()
+	b := &testBreaker{primary: &stubLocker{}, now: time.Unix(1700000000, 0)}
+	opts = append([]FallbackLockerOption{
+		WithFailureThreshold(3),
+		WithCooldown(5 * time.Second),
+		WithHealthCheck(func(ctx context.Context) error {
+			b.probes.Add(1)
+			if b.probe != nil {
+				return b.probe(ctx)
+			}
+			return nil
+		}),
+	}, opts...)
+	b.fallbackLocker = NewFallbackLocker(nil, b.primary, opts...).(*fallbackLocker)
+	b.fallbackLocker.now = func() time.Time { return b.now }
+	return b
+}
+
+func (b *testBreaker) lock(t *testing.T) error {
+	t.Helper()
+	_, err := b.TryLock(context.Background(), "k")
+	return err
+}
+
+func TestIsBackendError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: nil, want: false},
+		{err: ErrLockNotAcquired, want: false},
+		{err: ErrLockTimeout, want: false},
+		{err: ErrNotLockOwner, want: false},
+		{err: ErrLockerClosed, want: false},
+		{err: ErrDeadlock, want: false},
+		{err: fmt.Errorf("acquire: %w", ErrLockTimeout), want: false},
+		{err: context.Canceled, want: false},
+		{err: context.DeadlineExceeded, want: false},
+		{err: errRedisDown, want: true},
+	}
+	for _, tt := range tests {
+		if got := isBackendError(tt.err); got != tt.want {
+			t.Errorf("isBackendError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestFallbackLocker_BusinessErrorsDoNotTrip(t *testing.T) {
+	for _, err := range []error{ErrLockNotAcquired, ErrLockTimeout, ErrDeadlock, context.DeadlineExceeded} {
+		t.Run(err.Error(), func(t *testing.T) {
+			b := newTestBreaker(t, WithFailureThreshold(1))
+			b.primary.setErr(err)
+			for i := 0; i < 10; i++ {
+				if got := b.lock(t); !errors.Is(got, err) {
+					t.Fatalf("call %d error = %v, want %v", i, got, err)
+				}
+			}
+			if n := b.primary.callCount(); n != 10 {
+				t.Errorf("primary calls = %d, want 10 (breaker must stay closed)", n)
+			}
+		})
+	}
+}
+
+func TestFallbackLocker_Trip(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   FallbackPolicy
+		wantOpen func(t *testing.T, unlock UnLockFunc, err error)
+	}{
+		{
+			name:   "fail closed",
+			policy: FailClosed,
+			wantOpen: func(t *testing.T, unlock UnLockFunc, err error) {
+				if !errors.Is(err, ErrBackendUnavailable) {
+					t.Errorf("error = %v, want %v", err, ErrBackendUnavailable)
+				}
+			},
+		},
+		{
+			name:   "fail open",
+			policy: FailOpen,
+			wantOpen: func(t *testing.T, unlock UnLockFunc, err error) {
+				if err != nil || unlock == nil {
+					t.Fatalf("local lock = %v, %v", unlock, err)
+				}
+				if err := unlock(context.Background()); err != nil {
+					t.Errorf("local unlock error = %v", err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBreaker(t, WithFallbackPolicy(tt.policy))
+			b.primary.setErr(errRedisDown)
+
+			// 阈值之前的失败仍然访问 primary，并按策略降级
+			for i := 0; i < 3; i++ {
+				unlock, err := b.TryLock(context.Background(), "k")
+				tt.wantOpen(t, unlock, err)
+			}
+			if n := b.primary.callCount(); n != 3 {
+				t.Fatalf("primary calls = %d, want 3", n)
+			}
+
+			// 熔断后不再访问 primary
+			unlock, err := b.TryLock(context.Background(), "k")
+			tt.wantOpen(t, unlock, err)
+			if n := b.primary.callCount(); n != 3 {
+				t.Errorf("primary calls after trip = %d, want 3", n)
+			}
+		})
+	}
+}
+
+func TestFallbackLocker_SuccessResetsFailures(t *testing.T) {
+	b := newTestBreaker(t)
+	for _, err := range []error{errRedisDown, errRedisDown, nil, errRedisDown, errRedisDown} {
+		b.primary.setErr(err)
+		_ = b.lock(t)
+	}
+	b.primary.setErr(nil)
+	if err := b.lock(t); err != nil {
+		t.Fatalf("lock error = %v, want breaker still closed", err)
+	}
+	if n := b.primary.callCount(); n != 6 {
+		t.Errorf("primary calls = %d, want 6", n)
+	}
+}
+
+func TestFallbackLocker_Cooldown(t *testing.T) {
+	b := newTestBreaker(t)
+	b.primary.setErr(errRedisDown)
+	for i := 0; i < 3; i++ {
+		_ = b.lock(t)
+	}
+
+	// 冷却期内不探测
+	b.now = b.now.Add(5*time.Second - time.Millisecond)
+	if err := b.lock(t); !errors.Is(err, ErrBackendUnavailable) {
+		t.Fatalf("lock within cooldown error = %v", err)
+	}
+	if n := b.probes.Load(); n != 0 {
+		t.Fatalf("probes within cooldown = %d, want 0", n)
+	}
+
+	// 冷却结束探测失败，重新开始冷却
+	b.probe = func(context.Context) error { return errRedisDown }
+	b.now = b.now.Add(time.Millisecond)
+	if err := b.lock(t); !errors.Is(err, ErrBackendUnavailable) {
+		t.Fatalf("lock after failed probe error = %v", err)
+	}
+	if n := b.probes.Load(); n != 1 {
+		t.Fatalf("probes = %d, want 1", n)
+	}
+	b.now = b.now.Add(time.Second)
+	_ = b.lock(t)
+	if n := b.probes.Load(); n != 1 {
+		t.Fatalf("probes within the new cooldown = %d, want 1", n)
+	}
+
+	// 探测成功后切回 primary
+	b.probe = nil
+	b.primary.setErr(nil)
+	b.now = b.now.Add(5 * time.Second)
+	if err := b.lock(t); err != nil {
+		t.Fatalf("lock after recovery error = %v", err)
+	}
+	if n := b.primary.callCount(); n != 4 {
+		t.Errorf("primary calls = %d, want 4", n)
+	}
+	if err := b.lock(t); err != nil || b.probes.Load() != 2 {
+		t.Errorf("lock = %v, probes = %d, want closed breaker without probing", err, b.probes.Load())
+	}
+}
+
+func TestFallbackLocker_SingleHalfOpenProbe(t *testing.T) {
+	b := newTestBreaker(t)
+	b.primary.setErr(errRedisDown)
+	for i := 0; i < 3; i++ {
+		_ = b.lock(t)
+	}
+	b.primary.setErr(nil)
+
+	probing := make(chan struct{})
+	release := make(chan struct{})
+	b.probe = func(context.Context) error {
+		close(probing)
+		<-release
+		return nil
+	}
+	b.now = b.now.Add(5 * time.Second)
+
+	probeDone := make(chan error, 1)
+	go func() { probeDone <- b.lock(t) }()
+	<-probing
+
+	// 探测期间其他请求直接降级，不再探测
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := b.lock(t); !errors.Is(err, ErrBackendUnavailable) {
+				t.Errorf("lock during probe error = %v, want %v", err, ErrBackendUnavailable)
+			}
+		}()
+	}
+	wg.Wait()
+
+	close(release)
+	if err := <-probeDone; err != nil {
+		t.Fatalf("probing lock error = %v", err)
+	}
+	if n := b.probes.Load(); n != 1 {
+		t.Errorf("probes = %d, want 1", n)
+	}
+	if n := b.primary.callCount(); n != 4 {
+		t.Errorf("primary calls = %d, want 4", n)
+	}
+}
+
+func TestFallbackLocker_Close(t *testing.T) {
+	b := newTestBreaker(t, WithFallbackPolicy(FailOpen))
+	// stubLocker 没有实现 Closer，只关闭进程内的锁
+	if err := b.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	b.primary.setErr(errRedisDown)
+	if err := b.lock(t); !errors.Is(err, ErrLockerClosed) {
+		t.Errorf("fail-open lock after Close error = %v, want %v", err, ErrLockerClosed)
+	}
+}
@@ -0,0 +1,86 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Holder 锁的持有者信息
+type Holder struct {
+	Owner      string            `json:"owner,omitempty"`
+	AcquiredAt time.Time         `json:"acquired_at"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+}
+
+// HolderInspector 查询锁的持有者
+type HolderInspector interface {
+	// Holder 返回 key 当前的持有者，锁未被持有时返回 nil, nil
+	Holder(ctx context.Context, key string) (*Holder, error)
+}
+
+// 持有者信息保存在锁 key 旁边，与锁同时过期，看门狗续期时一起续期
+func metaKey(fullKey string) string {
+	return fullKey + ":meta"
+}
+
+// holderRecord 持有者信息 key 中保存的内容，LockValue 用于识别属于哪一次加锁
+type holderRecord struct {
+	Holder
+	LockValue string `json:"lock_value"`
+}
+
+// ownerOfLockValue 从 owner#uuid 格式的锁的值中解析 owner
+func ownerOfLockValue(lockValue string) string {
+	i := strings.LastIndex(lockValue, ownerSeparator)
+	if i < 0 {
+		return ""
+	}
+	return lockValue[:i]
+}
+
+// saveHolder 加锁成功后记录持有者信息
+func (l *redisLocker) saveHolder(ctx context.Context, fullKey, lockValue string, o *lockOptions) error {
+	b, err := json.Marshal(holderRecord{
+		Holder: Holder{
+			Owner:      o.owner,
+			AcquiredAt: time.Now(),
+			Metadata:   o.metadata,
+		},
+		LockValue: lockValue,
+	})
+	if err != nil {
+		return err
+	}
+	return l.rd.Set(ctx, metaKey(fullKey), b, o.ttl).Err()
+}
+
+// Holder 返回 key 当前的持有者，锁未被持有时返回 nil, nil
+func (l *redisLocker) Holder(ctx context.Context, key string) (*Holder, error) {
+	fullKey := l.buildFullKey(key)
+
+	// 同时读取锁与持有者信息，避免两次读取之间锁已易主
+	vals, err := l.rd.MGet(ctx, fullKey, metaKey(fullKey)).Result()
+	if err != nil {
+		return nil, err
+	}
+	lockValue, ok := vals[0].(string)
+	if !ok {
+		return nil, nil
+	}
+
+	holder := &Holder{Owner: ownerOfLockValue(lockValue)}
+	meta, ok := vals[1].(string)
+	if !ok {
+		return holder, nil // 加锁时没有附加信息
+	}
+	var rec holderRecord
+	if err := json.Unmarshal([]byte(meta), &rec); err != nil {
+		return nil, err
+	}
+	if rec.LockValue != lockValue {
+		return holder, nil // 之前的持有者留下的信息
+	}
+	return &rec.Holder, nil
+}
@@ -0,0 +1,146 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHolder(t *testing.T) {
+	mr, rd := newTestRedis(t)
+	l := NewRedisLocker(rd, WithKeyPrefix("test"))
+	inspector := l.(HolderInspector)
+	ctx := ContextWithOwner(context.Background(), "ctx-owner")
+
+	if h, err := inspector.Holder(ctx, "k"); h != nil || err != nil {
+		t.Fatalf("Holder() of a free key = %v, %v, want nil, nil", h, err)
+	}
+
+	before := time.Now()
+	meta := map[string]string{"host": "web-1"}
+	unlock, err := l.Lock(ctx, "k", WithOwner("job-1"), WithMetadata(meta), WithLockTTL(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := inspector.Holder(ctx, "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Owner != "job-1" || !reflect.DeepEqual(h.Metadata, meta) {
+		t.Errorf("Holder() = %+v, want owner job-1 with %v", h, meta)
+	}
+	if h.AcquiredAt.Before(before.Truncate(time.Second)) || h.AcquiredAt.After(time.Now()) {
+		t.Errorf("AcquiredAt = %v, want around %v", h.AcquiredAt, before)
+	}
+	// 持有者信息与锁同时过期
+	if got := mr.TTL("test:k:meta"); got != time.Minute {
+		t.Errorf("meta TTL = %v, want %v", got, time.Minute)
+	}
+
+	if err := unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists("test:k:meta") {
+		t.Error("meta key left after Unlock")
+	}
+	if h, err := inspector.Holder(ctx, "k"); h != nil || err != nil {
+		t.Errorf("Holder() after Unlock = %v, %v, want nil, nil", h, err)
+	}
+}
+
+func TestHolder_WithoutMetadata(t *testing.T) {
+	mr, rd := newTestRedis(t)
+	l := NewRedisLocker(rd, WithKeyPrefix("test"))
+	ctx := context.Background()
+
+	// 没有 owner 和附加信息时不写 meta key
+	unlock, err := l.Lock(ctx, "anon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock(ctx)
+	if mr.Exists("test:anon:meta") {
+		t.Error("meta key written without owner or metadata")
+	}
+	h, err := l.(HolderInspector).Holder(ctx, "anon")
+	if err != nil || h == nil || h.Owner != "" {
+		t.Errorf("Holder() = %+v, %v, want an anonymous holder", h, err)
+	}
+
+	// meta key 丢失时仍能从锁的值解析 owner
+	unlockOwned, err := l.Lock(ContextWithOwner(ctx, "job-2"), "owned")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlockOwned(ctx)
+	mr.Del("test:owned:meta")
+	h, err = l.(HolderInspector).Holder(ctx, "owned")
+	if err != nil || h == nil || h.Owner != "job-2" {
+		t.Errorf("Holder() without meta = %+v, %v, want owner job-2", h, err)
+	}
+}
+
+func TestHolder_StaleUnlockKeepsNewHolder(t *testing.T) {
+	mr, rd := newTestRedis(t)
+	l := NewRedisLocker(rd, WithKeyPrefix("test"))
+	ctx := context.Background()
+
+	unlockA, err := l.Lock(ctx, "k", WithOwner("A"), WithLockTTL(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A 的锁过期后被 B 获取
+	mr.FastForward(time.Second)
+	unlockB, err := l.Lock(ctx, "k", WithOwner("B"), WithMetadata(map[string]string{"host": "web-2"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := unlockA(ctx); !errors.Is(err, ErrNotLockOwner) {
+		t.Fatalf("stale unlock error = %v, want %v", err, ErrNotLockOwner)
+	}
+	h, err := l.(HolderInspector).Holder(ctx, "k")
+	if err != nil || h == nil || h.Owner != "B" || h.Metadata["host"] != "web-2" {
+		t.Errorf("Holder() after stale unlock = %+v, %v, want B with its metadata", h, err)
+	}
+
+	if err := unlockB(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists("test:k") || mr.Exists("test:k:meta") {
+		t.Error("lock or meta key left after Unlock")
+	}
+}
+
+func TestHolder_IgnoresStaleMetadata(t *testing.T) {
+	mr, rd := newTestRedis(t)
+	l := NewRedisLocker(rd, WithKeyPrefix("test"))
+	ctx := context.Background()
+
+	if _, err := l.Lock(ctx, "k", WithOwner("A"), WithMetadata(map[string]string{"host": "web-1"}), WithLockTTL(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	stale, err := mr.Get("test:k:meta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr.FastForward(time.Second)
+
+	// B 匿名加锁，A 的持有者信息残留（如 A 写入时与锁的过期时间不一致）
+	unlockB, err := l.Lock(ctx, "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlockB(ctx)
+	if err := mr.Set("test:k:meta", stale); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := l.(HolderInspector).Holder(ctx, "k")
+	if err != nil || h == nil || h.Owner != "" || h.Metadata != nil {
+		t.Errorf("Holder() with stale metadata = %+v, %v, want the anonymous holder B", h, err)
+	}
+}
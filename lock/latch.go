@@ -0,0 +1,91 @@
+package lock
+
+import (
+	"context"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 倒计时的 Lua 脚本：计数减一并刷新过期时间，归零时广播
+// KEYS[1] 计数 key；ARGV[1] ttl(ms)，ARGV[2] 通知 channel
+const latchCountDownScript = `
+local n = redis.call("get",KEYS[1])
+if not n then
+    return -1
+end
+if tonumber(n) <= 0 then
+    return 0
+end
+n = redis.call("decr",KEYS[1])
+redis.call("pexpire",KEYS[1],ARGV[1])
+if n <= 0 then
+    redis.call("publish",ARGV[2],0)
+end
+return n`
+
+// CountDownLatch 分布式倒计时门闩
+type CountDownLatch interface {
+	// CountDown 计数减一，归零时唤醒所有等待者
+	CountDown(ctx context.Context) error
+	// Await 阻塞直到计数归零
+	Await(ctx context.Context) error
+	// Count 返回当前剩余计数
+	Count(ctx context.Context) (int64, error)
+}
+
+type redisLatch struct {
+	c       *redisCoordinator
+	key     string
+	channel string
+}
+
+// CountDownLatch 创建计数为 count 的门闩，同名门闩已存在时直接加入，不会重置计数。
+// 超过 ttl 没有任何 CountDown 时门闩被清理，等待者返回 ErrLatchExpired
+func (c *redisCoordinator) CountDownLatch(ctx context.Context, name string, count int) (CountDownLatch, error) {
+	if count <= 0 {
+		return nil, ErrInvalidCount
+	}
+
+	l := &redisLatch{
+		c:       c,
+		key:     c.buildFullKey("latch", name, "count"),
+		channel: c.buildFullKey("latch", name, "notify"),
+	}
+	if err := c.rd.SetNX(ctx, l.key, count, c.ttl).Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// CountDown 计数减一
+func (l *redisLatch) CountDown(ctx context.Context) error {
+	n, err := l.c.rd.Eval(ctx, latchCountDownScript, []string{l.key}, l.c.ttl.Milliseconds(), l.channel).Int64()
+	if err != nil {
+		return err
+	}
+	if n < 0 {
+		return ErrLatchExpired
+	}
+	return nil
+}
+
+// Await 阻塞直到计数归零
+func (l *redisLatch) Await(ctx context.Context) error {
+	return l.c.waitFor(ctx, l.channel, func(ctx context.Context) (bool, error) {
+		n, err := l.Count(ctx)
+		if err != nil {
+			return false, err
+		}
+		return n <= 0, nil
+	})
+}
+
+// Count 返回当前剩余计数
+func (l *redisLatch) Count(ctx context.Context) (int64, error) {
+	n, err := l.c.rd.Get(ctx, l.key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrLatchExpired
+	}
+	return n, err
+}
@@ -0,0 +1,120 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// localLocker 进程内的锁，只在当前进程内互斥，用于 Redis 不可用时的降级
+type localLocker struct {
+	defaultTimeout time.Duration
+
+	mu     sync.Mutex
+	locks  map[string]*localLock
+	closed bool
+}
+
+type localLock struct {
+	ch   chan struct{} // 容量为 1 的信号量
+	refs int           // 持有者 + 等待者数量，归零时删除
+}
+
+func NewLocalLocker() Locker {
+	return &localLocker{
+		defaultTimeout: defaultTimeout,
+		locks:          make(map[string]*localLock),
+	}
+}
+
+// Lock 获取锁(阻塞式，使用默认超时时间)
+func (l *localLocker) Lock(ctx context.Context, key string, opts ...LockOption) (UnLockFunc, error) {
+	return l.lockBlocking(ctx, key, newLockOptions(ctx, 0, l.defaultTimeout, opts))
+}
+
+// TryLock 尝试获取锁(非阻塞)，未获取到锁时返回 nil, nil。
+// 进程内的锁没有过期时间，TTL、看门狗等选项不生效
+func (l *localLocker) TryLock(ctx context.Context, key string, opts ...LockOption) (UnLockFunc, error) {
+	e, err := l.ref(key)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case e.ch <- struct{}{}:
+		return l.unlockFunc(key, e), nil
+	default:
+		l.unref(key, e)
+		return nil, nil
+	}
+}
+
+// LockWithTimeout 在超时时间内等待获取锁（阻塞式）
+func (l *localLocker) LockWithTimeout(ctx context.Context, key string, timeout time.Duration, opts ...LockOption) (UnLockFunc, error) {
+	return l.lockBlocking(ctx, key, newLockOptions(ctx, 0, timeout, opts))
+}
+
+func (l *localLocker) lockBlocking(ctx context.Context, key string, o *lockOptions) (UnLockFunc, error) {
+	e, err := l.ref(key)
+	if err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(o.waitTimeout)
+	defer timer.Stop()
+
+	select {
+	case e.ch <- struct{}{}:
+		return l.unlockFunc(key, e), nil
+	case <-ctx.Done():
+		l.unref(key, e)
+		return nil, ctx.Err()
+	case <-timer.C:
+		l.unref(key, e)
+		return nil, ErrLockTimeout
+	}
+}
+
+// Close 之后的获取锁操作都返回 ErrLockerClosed，进程内的锁随进程退出自然释放
+func (l *localLocker) Close(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	return nil
+}
+
+func (l *localLocker) ref(key string) (*localLock, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil, ErrLockerClosed
+	}
+	e, ok := l.locks[key]
+	if !ok {
+		e = &localLock{ch: make(chan struct{}, 1)}
+		l.locks[key] = e
+	}
+	e.refs++
+	return e, nil
+}
+
+func (l *localLocker) unref(key string, e *localLock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e.refs--
+	if e.refs == 0 {
+		delete(l.locks, key)
+	}
+}
+
+func (l *localLocker) unlockFunc(key string, e *localLock) UnLockFunc {
+	var once sync.Once
+	return func(ctx context.Context) error {
+		err := ErrNotLockOwner
+		once.Do(func() {
+			<-e.ch
+			l.unref(key, e)
+			err = nil
+		})
+		return err
+	}
+}
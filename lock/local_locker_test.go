@@ -0,0 +1,54 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLocalLocker(t *testing.T) {
+	l := NewLocalLocker().(*localLocker)
+	ctx := context.Background()
+
+	unlock, err := l.TryLock(ctx, "k")
+	if err != nil || unlock == nil {
+		t.Fatalf("TryLock() = %v, %v", unlock, err)
+	}
+	// 已被持有时 TryLock 返回 nil, nil，阻塞获取超时
+	if u, err := l.TryLock(ctx, "k"); u != nil || err != nil {
+		t.Fatalf("TryLock() on a held key = %v, %v, want nil, nil", u, err)
+	}
+	if _, err := l.LockWithTimeout(ctx, "k", 20*time.Millisecond); !errors.Is(err, ErrLockTimeout) {
+		t.Fatalf("LockWithTimeout() error = %v, want %v", err, ErrLockTimeout)
+	}
+
+	// 释放后等待者获得锁
+	got := make(chan error, 1)
+	go func() {
+		u, err := l.LockWithTimeout(ctx, "k", time.Second)
+		if err == nil {
+			err = u(ctx)
+		}
+		got <- err
+	}()
+	if err := unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-got; err != nil {
+		t.Fatalf("waiter error = %v", err)
+	}
+	if err := unlock(ctx); !errors.Is(err, ErrNotLockOwner) {
+		t.Errorf("second unlock error = %v, want %v", err, ErrNotLockOwner)
+	}
+	if n := len(l.locks); n != 0 {
+		t.Errorf("%d lock entries left after release", n)
+	}
+
+	if err := l.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.TryLock(ctx, "k"); !errors.Is(err, ErrLockerClosed) {
+		t.Errorf("TryLock() after Close error = %v, want %v", err, ErrLockerClosed)
+	}
+}
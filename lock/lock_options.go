@@ -0,0 +1,120 @@
+package lock
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy 返回第 attempt 次（从 1 开始）重试前需要等待的时间
+type RetryPolicy func(attempt int) time.Duration
+
+// ExponentialBackoff 指数退避，从 base 开始每次翻倍，最大不超过 max
+func ExponentialBackoff(base, max time.Duration) RetryPolicy {
+	return func(attempt int) time.Duration {
+		interval := base
+		for i := 1; i < attempt && interval < max; i++ {
+			interval *= 2
+		}
+		if interval > max {
+			interval = max
+		}
+		return interval
+	}
+}
+
+// FixedInterval 固定间隔重试
+func FixedInterval(interval time.Duration) RetryPolicy {
+	return func(int) time.Duration {
+		return interval
+	}
+}
+
+// Jitter 在 policy 的基础上随机缩短等待时间，结果落在 [d*(1-factor), d]，
+// 避免大量等待者同时重试；factor 取值 [0, 1]，超出范围时按边界处理
+func Jitter(policy RetryPolicy, factor float64) RetryPolicy {
+	factor = min(max(factor, 0), 1)
+	return func(attempt int) time.Duration {
+		d := policy(attempt)
+		return d - time.Duration(rand.Float64()*factor*float64(d))
+	}
+}
+
+// 单次加锁的最小过期时间
+const minLockTTL = time.Millisecond
+
+// 默认重试策略：10ms 开始翻倍，最大 160ms
+var defaultRetryPolicy = ExponentialBackoff(10*time.Millisecond, 160*time.Millisecond)
+
+// lockOptions 单次获取锁的参数，未设置的字段使用 locker 的默认值
+type lockOptions struct {
+	ttl         time.Duration
+	waitTimeout time.Duration
+	owner       string
+	metadata    map[string]string
+	retry       RetryPolicy
+	watchdog    bool
+}
+
+// LockOption 单次获取锁的选项
+type LockOption func(o *lockOptions)
+
+// WithLockTTL 设置本次加锁的过期时间，不大于 0 时使用 locker 的默认值，最小为 1ms
+func WithLockTTL(ttl time.Duration) LockOption {
+	return func(o *lockOptions) {
+		o.ttl = ttl
+	}
+}
+
+// WithWaitTimeout 设置本次等待锁的超时时间，对 TryLock 无效
+func WithWaitTimeout(timeout time.Duration) LockOption {
+	return func(o *lockOptions) {
+		o.waitTimeout = timeout
+	}
+}
+
+// WithOwner 设置本次加锁的持锁者标识，优先于 ContextWithOwner
+func WithOwner(owner string) LockOption {
+	return func(o *lockOptions) {
+		o.owner = owner
+	}
+}
+
+// WithMetadata 记录持锁者的附加信息（如主机名、请求 ID），可通过 Holder 查询
+func WithMetadata(metadata map[string]string) LockOption {
+	return func(o *lockOptions) {
+		o.metadata = metadata
+	}
+}
+
+// WithRetryPolicy 设置本次等待锁时的重试间隔
+func WithRetryPolicy(retry RetryPolicy) LockOption {
+	return func(o *lockOptions) {
+		o.retry = retry
+	}
+}
+
+// WithWatchdog 开启/关闭看门狗，开启后持有期间每 ttl/3 自动续期，直到解锁
+func WithWatchdog(enabled bool) LockOption {
+	return func(o *lockOptions) {
+		o.watchdog = enabled
+	}
+}
+
+func newLockOptions(ctx context.Context, ttl, waitTimeout time.Duration, opts []LockOption) *lockOptions {
+	o := &lockOptions{
+		ttl:         ttl,
+		waitTimeout: waitTimeout,
+		retry:       defaultRetryPolicy,
+	}
+	o.owner, _ = OwnerFromContext(ctx)
+	for _, opt := range opts {
+		opt(o)
+	}
+	// Redis 的过期时间最小为 1ms，看门狗按 ttl/3 计时也要求 ttl 为正数
+	if o.ttl <= 0 {
+		o.ttl = ttl
+	}
+	o.ttl = max(o.ttl, minLockTTL)
+	return o
+}
@@ -0,0 +1,117 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		want   []time.Duration // 第 1..n 次重试的等待时间
+	}{
+		{
+			name:   "exponential growth",
+			policy: ExponentialBackoff(10*ms, time.Second),
+			want:   []time.Duration{10 * ms, 20 * ms, 40 * ms, 80 * ms, 160 * ms},
+		},
+		{
+			name:   "exponential cap",
+			policy: ExponentialBackoff(10*ms, 50*ms),
+			want:   []time.Duration{10 * ms, 20 * ms, 40 * ms, 50 * ms, 50 * ms, 50 * ms},
+		},
+		{
+			name:   "base above max",
+			policy: ExponentialBackoff(100*ms, 50*ms),
+			want:   []time.Duration{50 * ms, 50 * ms},
+		},
+		{
+			name:   "default",
+			policy: defaultRetryPolicy,
+			want:   []time.Duration{10 * ms, 20 * ms, 40 * ms, 80 * ms, 160 * ms, 160 * ms},
+		},
+		{
+			name:   "fixed interval",
+			policy: FixedInterval(25 * ms),
+			want:   []time.Duration{25 * ms, 25 * ms, 25 * ms},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, want := range tt.want {
+				if got := tt.policy(i + 1); got != want {
+					t.Errorf("attempt %d = %v, want %v", i+1, got, want)
+				}
+			}
+		})
+	}
+
+	// 大的 attempt 不会溢出
+	if got := ExponentialBackoff(10*ms, time.Second)(1000); got != time.Second {
+		t.Errorf("attempt 1000 = %v, want %v", got, time.Second)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name   string
+		factor float64
+		min    time.Duration
+	}{
+		{name: "none", factor: 0, min: 100 * ms},
+		{name: "half", factor: 0.5, min: 50 * ms},
+		{name: "full", factor: 1, min: 0},
+		{name: "negative", factor: -1, min: 100 * ms},
+		{name: "above one", factor: 2, min: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := Jitter(FixedInterval(100*ms), tt.factor)
+			varied := false
+			for i := 0; i < 1000; i++ {
+				d := policy(1)
+				if d < tt.min || d > 100*ms {
+					t.Fatalf("jittered interval %v out of [%v, %v]", d, tt.min, 100*ms)
+				}
+				varied = varied || d != 100*ms
+			}
+			if varied != (tt.min < 100*ms) {
+				t.Errorf("jitter applied = %v, want %v", varied, tt.min < 100*ms)
+			}
+		})
+	}
+
+	// 上限跟随被包装的策略
+	policy := Jitter(ExponentialBackoff(10*ms, 40*ms), 0.5)
+	for attempt, max := range []time.Duration{10 * ms, 20 * ms, 40 * ms, 40 * ms} {
+		if d := policy(attempt + 1); d < max/2 || d > max {
+			t.Errorf("attempt %d = %v, want within [%v, %v]", attempt+1, d, max/2, max)
+		}
+	}
+}
+
+func TestNewLockOptions_TTL(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []LockOption
+		want time.Duration
+	}{
+		{name: "default", want: 5 * time.Second},
+		{name: "per call", opts: []LockOption{WithLockTTL(time.Minute)}, want: time.Minute},
+		{name: "zero uses default", opts: []LockOption{WithLockTTL(0)}, want: 5 * time.Second},
+		{name: "negative uses default", opts: []LockOption{WithLockTTL(-time.Second)}, want: 5 * time.Second},
+		{name: "clamped", opts: []LockOption{WithLockTTL(time.Nanosecond)}, want: minLockTTL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newLockOptions(context.Background(), 5*time.Second, time.Second, tt.opts)
+			if o.ttl != tt.want {
+				t.Errorf("ttl = %v, want %v", o.ttl, tt.want)
+			}
+		})
+	}
+}
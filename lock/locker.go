@@ -9,9 +9,24 @@ type UnLockFunc func(ctx context.Context) error
 
 type Locker interface {
 	// Lock 获取锁（阻塞式，使用默认超时时间）
-	Lock(ctx context.Context, key string) (UnLockFunc, error)
+	Lock(ctx context.Context, key string, opts ...LockOption) (UnLockFunc, error)
 	// TryLock 尝试获取锁（非阻塞，立即返回）
-	TryLock(ctx context.Context, key string) (UnLockFunc, error)
-	// LockWithTimeout 在超时时间内等待获取锁（阻塞式）
-	LockWithTimeout(ctx context.Context, key string, timeout time.Duration) (UnLockFunc, error)
+	TryLock(ctx context.Context, key string, opts ...LockOption) (UnLockFunc, error)
+	// LockWithTimeout 在超时时间内等待获取锁（阻塞式），opts 中的 WithWaitTimeout 会覆盖 timeout
+	LockWithTimeout(ctx context.Context, key string, timeout time.Duration, opts ...LockOption) (UnLockFunc, error)
+}
+
+// Closer 释放当前持有的所有锁，之后的获取锁操作都返回 ErrLockerClosed。
+// 不是所有 Locker 都持有需要释放的资源，因此单独定义；
+// NewRedisLocker、NewLocalLocker、NewFallbackLocker 返回的 Locker 都实现了 Closer
+type Closer interface {
+	Close(ctx context.Context) error
+}
+
+// closeLocker l 实现了 Closer 时关闭它
+func closeLocker(ctx context.Context, l Locker) error {
+	if c, ok := l.(Closer); ok {
+		return c.Close(ctx)
+	}
+	return nil
 }
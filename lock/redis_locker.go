@@ -4,9 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -14,6 +14,7 @@ var (
 	ErrLockNotAcquired = errors.New("failed to acquire lock")
 	ErrLockTimeout     = errors.New("lock timeout")
 	ErrNotLockOwner    = errors.New("not the lock owner")
+	ErrLockerClosed    = errors.New("locker closed")
 )
 
 var (
@@ -22,8 +23,10 @@ var (
 )
 
 // 解锁的 Lua 脚本，确保只删除自己持有的锁
+// KEYS[1] 锁 key，KEYS[2] 持有者信息 key，与锁一起删除，避免误删下一个持有者的信息
 const unlockScript = `
 if redis.call("get",KEYS[1]) == ARGV[1] then
+    redis.call("del",KEYS[2])
     return redis.call("del",KEYS[1])
 else
     return 0
@@ -34,6 +37,16 @@ type redisLocker struct {
 	keyPrefix      string
 	ttl            time.Duration
 	defaultTimeout time.Duration // 默认等待锁的超时时间
+	detectDeadlock bool          // 是否开启死锁检测
+
+	mu     sync.Mutex
+	held   map[string]*heldLock // 当前持有的锁：lockValue -> heldLock
+	closed bool
+}
+
+type heldLock struct {
+	fullKey      string
+	stopWatchdog func() // 未开启看门狗时为 nil
 }
 
 type RedisLockerOption func(l *redisLocker)
@@ -62,6 +75,7 @@ func NewRedisLocker(rd *redis.Client, opts ...RedisLockerOption) Locker {
 		keyPrefix:      "",
 		ttl:            defaultTTL,
 		defaultTimeout: defaultTimeout, // 默认等待30秒
+		held:           make(map[string]*heldLock),
 	}
 	for _, opt := range opts {
 		opt(l)
@@ -78,18 +92,22 @@ func (l *redisLocker) buildFullKey(key string) string {
 }
 
 // Lock 获取锁(阻塞式，使用默认超时时间)
-func (l *redisLocker) Lock(ctx context.Context, key string) (UnLockFunc, error) {
-	return l.LockWithTimeout(ctx, key, l.defaultTimeout)
+func (l *redisLocker) Lock(ctx context.Context, key string, opts ...LockOption) (UnLockFunc, error) {
+	return l.lockBlocking(ctx, key, newLockOptions(ctx, l.ttl, l.defaultTimeout, opts))
 }
 
 // lockNonBlocking 非阻塞获取锁（内部方法）
-func (l *redisLocker) lockNonBlocking(ctx context.Context, key string) (UnLockFunc, error) {
+func (l *redisLocker) lockNonBlocking(ctx context.Context, key string, o *lockOptions) (UnLockFunc, error) {
+	if l.isClosed() {
+		return nil, ErrLockerClosed
+	}
+
 	fullKey := l.buildFullKey(key)
 	// 生成唯一的锁标识
-	lockValue := uuid.New().String()
+	lockValue := newLockValue(o.owner)
 
 	// 使用SET命令的NX选项实现原子性加锁
-	result := l.rd.SetNX(ctx, fullKey, lockValue, l.ttl)
+	result := l.rd.SetNX(ctx, fullKey, lockValue, o.ttl)
 	if result.Err() != nil {
 		return nil, result.Err()
 	}
@@ -98,15 +116,33 @@ func (l *redisLocker) lockNonBlocking(ctx context.Context, key string) (UnLockFu
 		return nil, ErrLockNotAcquired
 	}
 
+	h := &heldLock{fullKey: fullKey}
+
+	// 记录持有者信息，失败不影响加锁结果
+	if o.owner != "" || len(o.metadata) > 0 {
+		_ = l.saveHolder(ctx, fullKey, lockValue, o)
+	}
+
+	if o.watchdog {
+		h.stopWatchdog = l.startWatchdog(fullKey, lockValue, o.ttl)
+	}
+
+	// 记录持有的锁，Close 时统一释放
+	if !l.track(lockValue, h) {
+		// 加锁期间 locker 被关闭，立即释放刚拿到的锁
+		_ = l.unlock(ctx, h, lockValue)
+		return nil, ErrLockerClosed
+	}
+
 	// 返回解锁函数，使用闭包保存锁的值
 	return func(ctx context.Context) error {
-		return l.unlock(ctx, fullKey, lockValue)
+		return l.release(ctx, h, lockValue)
 	}, nil
 }
 
 // TryLock 尝试获取锁(非阻塞)
-func (l *redisLocker) TryLock(ctx context.Context, key string) (UnLockFunc, error) {
-	unLockFunc, err := l.lockNonBlocking(ctx, key)
+func (l *redisLocker) TryLock(ctx context.Context, key string, opts ...LockOption) (UnLockFunc, error) {
+	unLockFunc, err := l.lockNonBlocking(ctx, key, newLockOptions(ctx, l.ttl, 0, opts))
 	if err != nil {
 		if errors.Is(err, ErrLockNotAcquired) {
 			return nil, nil // 未获取到锁，但不是错误
@@ -117,11 +153,21 @@ func (l *redisLocker) TryLock(ctx context.Context, key string) (UnLockFunc, erro
 }
 
 // LockWithTimeout 在超时时间内等待获取锁（阻塞式）
-func (l *redisLocker) LockWithTimeout(ctx context.Context, key string, timeout time.Duration) (UnLockFunc, error) {
-	deadline := time.Now().Add(timeout)
-	retryInterval := 10 * time.Millisecond // 重试间隔
+func (l *redisLocker) LockWithTimeout(ctx context.Context, key string, timeout time.Duration, opts ...LockOption) (UnLockFunc, error) {
+	return l.lockBlocking(ctx, key, newLockOptions(ctx, l.ttl, timeout, opts))
+}
 
-	for time.Now().Before(deadline) {
+// lockBlocking 在 o.waitTimeout 内按重试策略等待获取锁（内部方法）
+func (l *redisLocker) lockBlocking(ctx context.Context, key string, o *lockOptions) (UnLockFunc, error) {
+	deadline := time.Now().Add(o.waitTimeout)
+
+	// 开启死锁检测时记录等待边，结束等待后清理
+	detect := l.detectDeadlock && o.owner != ""
+	if detect {
+		defer l.clearWaitFor(ctx, o.owner)
+	}
+
+	for attempt := 1; time.Now().Before(deadline); attempt++ {
 		// 检查 context 是否已取消
 		select {
 		case <-ctx.Done():
@@ -130,7 +176,7 @@ func (l *redisLocker) LockWithTimeout(ctx context.Context, key string, timeout t
 		}
 
 		// 尝试获取锁
-		unlockFunc, err := l.lockNonBlocking(ctx, key)
+		unlockFunc, err := l.lockNonBlocking(ctx, key, o)
 		if err == nil {
 			return unlockFunc, nil // 成功获取锁
 		}
@@ -140,23 +186,30 @@ func (l *redisLocker) LockWithTimeout(ctx context.Context, key string, timeout t
 			return nil, err
 		}
 
+		if detect {
+			if err := l.checkDeadlock(ctx, l.buildFullKey(key), o.owner, o.waitTimeout); err != nil {
+				return nil, err
+			}
+		}
+
 		// 等待一段时间后重试
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
-		case <-time.After(retryInterval):
-			// 动态调整重试间隔，避免过于频繁的请求
-			if retryInterval < 100*time.Millisecond {
-				retryInterval *= 2
-			}
+		case <-time.After(o.retry(attempt)):
+			// 按重试策略调整间隔，避免过于频繁的请求
 		}
 	}
 	return nil, ErrLockTimeout
 }
 
 // unlock 内部解锁方法，使用 Lua 脚本确保原子性
-func (l *redisLocker) unlock(ctx context.Context, fullKey, lockValue string) error {
-	result := l.rd.Eval(ctx, unlockScript, []string{fullKey}, lockValue)
+func (l *redisLocker) unlock(ctx context.Context, h *heldLock, lockValue string) error {
+	if h.stopWatchdog != nil {
+		h.stopWatchdog()
+	}
+
+	result := l.rd.Eval(ctx, unlockScript, []string{h.fullKey, metaKey(h.fullKey)}, lockValue)
 	if result.Err() != nil {
 		return result.Err()
 	}
@@ -165,6 +218,54 @@ func (l *redisLocker) unlock(ctx context.Context, fullKey, lockValue string) err
 	if result.Val().(int64) == 0 {
 		return ErrNotLockOwner
 	}
-
 	return nil
 }
+
+// Close 释放当前持有的所有锁，之后的获取锁操作都返回 ErrLockerClosed，可重复调用
+func (l *redisLocker) Close(ctx context.Context) error {
+	l.mu.Lock()
+	l.closed = true
+	held := l.held
+	l.held = make(map[string]*heldLock)
+	l.mu.Unlock()
+
+	var errs []error
+	for lockValue, h := range held {
+		// 锁已过期被他人获取时不算错误
+		if err := l.unlock(ctx, h, lockValue); err != nil && !errors.Is(err, ErrNotLockOwner) {
+			errs = append(errs, fmt.Errorf("release %s: %w", h.fullKey, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (l *redisLocker) isClosed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closed
+}
+
+// track 记录持有的锁，locker 已关闭时返回 false
+func (l *redisLocker) track(lockValue string, h *heldLock) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return false
+	}
+	l.held[lockValue] = h
+	return true
+}
+
+// release 解锁并取消记录，锁已被 Close 释放时直接返回
+func (l *redisLocker) release(ctx context.Context, h *heldLock, lockValue string) error {
+	l.mu.Lock()
+	_, ok := l.held[lockValue]
+	delete(l.held, lockValue)
+	closed := l.closed
+	l.mu.Unlock()
+
+	if !ok && closed {
+		return nil
+	}
+	return l.unlock(ctx, h, lockValue)
+}
@@ -0,0 +1,79 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRedisLocker_Close(t *testing.T) {
+	mr, rd := newTestRedis(t)
+	l := NewRedisLocker(rd, WithKeyPrefix("test"))
+	ctx := context.Background()
+
+	unlockA, err := l.Lock(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlockB, err := l.TryLock(ctx, "b", WithWatchdog(true), WithOwner("job-1"))
+	if err != nil || unlockB == nil {
+		t.Fatalf("TryLock() = %v, %v", unlockB, err)
+	}
+	unlockC, err := l.Lock(ctx, "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := unlockC(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := l.(Closer).Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	for _, key := range []string{"test:a", "test:b", "test:b:meta"} {
+		if mr.Exists(key) {
+			t.Errorf("%s still exists after Close", key)
+		}
+	}
+
+	// 已被 Close 释放的锁再解锁返回 nil
+	if err := unlockA(ctx); err != nil {
+		t.Errorf("unlock after Close error = %v", err)
+	}
+	if err := unlockB(ctx); err != nil {
+		t.Errorf("unlock after Close error = %v", err)
+	}
+
+	if _, err := l.Lock(ctx, "a"); !errors.Is(err, ErrLockerClosed) {
+		t.Errorf("Lock() after Close error = %v, want %v", err, ErrLockerClosed)
+	}
+	if _, err := l.TryLock(ctx, "a"); !errors.Is(err, ErrLockerClosed) {
+		t.Errorf("TryLock() after Close error = %v, want %v", err, ErrLockerClosed)
+	}
+	if err := l.(Closer).Close(ctx); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+}
+
+func TestRedisLocker_CloseSkipsLostLocks(t *testing.T) {
+	mr, rd := newTestRedis(t)
+	l := NewRedisLocker(rd, WithTTL(time.Second))
+	ctx := context.Background()
+
+	if _, err := l.Lock(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	// 锁过期后被他人持有
+	mr.FastForward(2 * time.Second)
+	if err := mr.Set("a", "other"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := l.(Closer).Close(ctx); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if v, _ := mr.Get("a"); v != "other" {
+		t.Errorf("Close released a lock held by someone else, value = %q", v)
+	}
+}
@@ -0,0 +1,41 @@
+package lock
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// CloseOnSignal 基于 signal.NotifyContext 创建 context，收到信号（或 parent 结束）时
+// 在 timeout 内调用 l.Close 释放所有持有的锁。调用返回的 stop 后不再释放。
+//
+//	ctx, stop := lock.CloseOnSignal(context.Background(), locker.(lock.Closer), 5*time.Second, syscall.SIGTERM, os.Interrupt)
+//	defer stop()
+func CloseOnSignal(parent context.Context, l Closer, timeout time.Duration, signals ...os.Signal) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(parent, signals...)
+
+	stopped := make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			select {
+			case <-stopped:
+				return // 用户主动 stop，不释放锁
+			default:
+			}
+			closeCtx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			_ = l.Close(closeCtx)
+		case <-stopped:
+		}
+	}()
+
+	return ctx, func() {
+		once.Do(func() { close(stopped) })
+		stop()
+	}
+}
@@ -0,0 +1,96 @@
+//go:build unix
+
+package lock
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// closerFunc 记录 Close 调用
+type closerFunc func(ctx context.Context) error
+
+func (f closerFunc) Close(ctx context.Context) error {
+	return f(ctx)
+}
+
+func newTestCloser() (Closer, chan struct{}) {
+	closed := make(chan struct{}, 1)
+	return closerFunc(func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			panic("Close called without a deadline")
+		}
+		closed <- struct{}{}
+		return nil
+	}), closed
+}
+
+func TestCloseOnSignal(t *testing.T) {
+	t.Run("signal", func(t *testing.T) {
+		c, closed := newTestCloser()
+		ctx, stop := CloseOnSignal(context.Background(), c, time.Second, syscall.SIGUSR1)
+		defer stop()
+
+		if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-closed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Close not called after signal")
+		}
+		if ctx.Err() == nil {
+			t.Error("ctx not done after signal")
+		}
+	})
+
+	t.Run("parent done", func(t *testing.T) {
+		c, closed := newTestCloser()
+		parent, cancel := context.WithCancel(context.Background())
+		_, stop := CloseOnSignal(parent, c, time.Second, syscall.SIGUSR1)
+		defer stop()
+
+		cancel()
+		select {
+		case <-closed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Close not called after parent done")
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		c, closed := newTestCloser()
+		_, stop := CloseOnSignal(context.Background(), c, time.Second, syscall.SIGUSR1)
+		stop()
+		stop() // 可重复调用
+
+		select {
+		case <-closed:
+			t.Fatal("Close called after stop")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("redis locker", func(t *testing.T) {
+		mr, rd := newTestRedis(t)
+		l := NewRedisLocker(rd)
+		if _, err := l.Lock(context.Background(), "a"); err != nil {
+			t.Fatal(err)
+		}
+		parent, cancel := context.WithCancel(context.Background())
+		_, stop := CloseOnSignal(parent, l.(Closer), time.Second)
+		defer stop()
+
+		cancel()
+		deadline := time.Now().Add(5 * time.Second)
+		for mr.Exists("a") {
+			if time.Now().After(deadline) {
+				t.Fatal("lock not released after parent done")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// 续期的 Lua 脚本，确保只续期自己持有的锁，持有者信息（KEYS[2]）一起续期
+const renewScript = `
+if redis.call("get",KEYS[1]) == ARGV[1] then
+    redis.call("pexpire",KEYS[2],ARGV[2])
+    return redis.call("pexpire",KEYS[1],ARGV[2])
+else
+    return 0
+end`
+
+// startWatchdog 每 ttl/3 续期一次，锁已不属于自己时自动退出，返回停止函数
+func (l *redisLocker) startWatchdog(fullKey, lockValue string, ttl time.Duration) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			ok, err := l.rd.Eval(ctx, renewScript, []string{fullKey, metaKey(fullKey)}, lockValue, ttl.Milliseconds()).Int64()
+			if err != nil {
+				continue // 网络抖动，下次再试
+			}
+			if ok == 0 {
+				return // 锁已过期或被他人持有
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
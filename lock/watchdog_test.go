@@ -0,0 +1,104 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// waitRenewed 等待看门狗把 key 的 TTL 续回 ttl
+func waitRenewed(t *testing.T, mr *miniredis.Miniredis, key string, ttl time.Duration) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for mr.TTL(key) != ttl {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s not renewed, TTL = %v", key, mr.TTL(key))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWatchdog(t *testing.T) {
+	mr, rd := newTestRedis(t)
+	l := NewRedisLocker(rd, WithKeyPrefix("test"))
+	ctx := context.Background()
+	const ttl = 150 * time.Millisecond
+
+	unlock, err := l.TryLock(ctx, "k", WithLockTTL(ttl), WithWatchdog(true), WithOwner("job-1"))
+	if err != nil || unlock == nil {
+		t.Fatalf("TryLock() = %v, %v", unlock, err)
+	}
+
+	// miniredis 的时间只随 FastForward 前进，累计前进超过 TTL 数倍后锁仍然存在
+	for i := 0; i < 5; i++ {
+		mr.FastForward(ttl * 2 / 3)
+		waitRenewed(t, mr, "test:k", ttl)
+		waitRenewed(t, mr, "test:k:meta", ttl)
+	}
+
+	if err := unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists("test:k") || mr.Exists("test:k:meta") {
+		t.Fatal("lock still exists after Unlock")
+	}
+
+	// Unlock 后看门狗不再发送续期命令
+	n := mr.CommandCount()
+	time.Sleep(ttl)
+	if got := mr.CommandCount(); got != n {
+		t.Errorf("%d commands sent after Unlock", got-n)
+	}
+}
+
+func TestWatchdog_StopsWhenLockLost(t *testing.T) {
+	mr, rd := newTestRedis(t)
+	l := NewRedisLocker(rd, WithKeyPrefix("test"))
+	ctx := context.Background()
+	const ttl = 150 * time.Millisecond
+
+	unlock, err := l.TryLock(ctx, "k", WithLockTTL(ttl), WithWatchdog(true))
+	if err != nil || unlock == nil {
+		t.Fatalf("TryLock() = %v, %v", unlock, err)
+	}
+
+	// 锁被他人持有后看门狗续期失败并退出，不会续期他人的锁
+	if err := mr.Set("test:k", "other"); err != nil {
+		t.Fatal(err)
+	}
+	mr.SetTTL("test:k", time.Minute)
+	time.Sleep(ttl)
+	n := mr.CommandCount()
+	time.Sleep(ttl)
+	if got := mr.CommandCount(); got != n {
+		t.Errorf("%d commands sent after the lock was lost", got-n)
+	}
+	if got := mr.TTL("test:k"); got != time.Minute {
+		t.Errorf("TTL of the other holder = %v, want %v", got, time.Minute)
+	}
+
+	if err := unlock(ctx); !errors.Is(err, ErrNotLockOwner) {
+		t.Errorf("Unlock() of a lost lock error = %v, want %v", err, ErrNotLockOwner)
+	}
+}
+
+func TestWatchdog_TinyTTL(t *testing.T) {
+	_, rd := newTestRedis(t)
+	l := NewRedisLocker(rd, WithKeyPrefix("test"))
+	ctx := context.Background()
+
+	// 过小的 TTL 不会让看门狗的 goroutine panic
+	for _, ttl := range []time.Duration{0, time.Nanosecond} {
+		unlock, err := l.TryLock(ctx, "k", WithLockTTL(ttl), WithWatchdog(true))
+		if err != nil || unlock == nil {
+			t.Fatalf("TryLock(ttl %v) = %v, %v", ttl, unlock, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+		if err := unlock(ctx); err != nil {
+			t.Errorf("unlock(ttl %v) error = %v", ttl, err)
+		}
+	}
+}